@@ -1,6 +1,8 @@
 package v1alpha1
 
 import (
+	"time"
+
 	"github.com/katallaxie/pkg/utilx"
 	corev1 "k8s.io/api/core/v1"
 )
@@ -11,15 +13,25 @@ const (
 )
 
 const (
-	ConditionTypeSynchronizing = "Sychronizing"
-	ConditionTypeSynchronized  = "Synchronized"
-	ConditionTypeFailed        = "Failed"
+	ConditionTypeSynchronizing   = "Sychronizing"
+	ConditionTypeSynchronized    = "Synchronized"
+	ConditionTypeFailed          = "Failed"
+	ConditionTypeTrusted         = "Trusted"
+	ConditionTypeImported        = "Imported"
+	ConditionTypeForbidden       = "Forbidden"
+	ConditionTypeRotationBlocked = "RotationBlocked"
 )
 
 const (
 	ConditionReasonCreated      = "Created"
 	ConditionReasonSynchronized = "Synchronized"
 	ConditionReasonFailed       = "Failed"
+	ConditionReasonTrusted      = "Trusted"
+	ConditionReasonUntrusted    = "Untrusted"
+	ConditionReasonImported     = "Imported"
+	ConditionReasonUnresolved   = "Unresolved"
+	ConditionReasonForbidden    = "NamespaceNotAllowed"
+	ConditionReasonIdentityKey  = "IdentityKey"
 )
 
 const (
@@ -58,10 +70,54 @@ const (
 	SecretNameKey             = "natz.katallaxie.dev/nats-key"
 	SecretUserCredentialsName = "natz.katallaxie.dev/nats-user-credentials"
 	SecretConfigKey           = "natz.katallaxie.dev/nats-config"
+	SecretJWTName             = "natz.katallaxie.dev/nats-jwt"
 )
 
+// PublishKind is the kind of resource the JWT is published into.
+//
+// +enum
+// +kubebuilder:validation:Enum={Secret,ConfigMap}
+type PublishKind string
+
+const (
+	PublishKindSecret    PublishKind = "Secret"
+	PublishKindConfigMap PublishKind = "ConfigMap"
+)
+
+// PublishSpec publishes the JWT and the public key of a resource into a Secret or ConfigMap.
+type PublishSpec struct {
+	// Kind is the kind of the published resource, it defaults to a Secret.
+	Kind PublishKind `json:"kind,omitempty"`
+	// Name is the name of the published resource, it defaults to the name of the resource with a -jwt suffix.
+	Name string `json:"name,omitempty"`
+	// JWTKey is the key of the JWT, it defaults to the kind of the resource, e.g. account.jwt.
+	JWTKey string `json:"jwtKey,omitempty"`
+	// PublicKeyKey is the key of the public key, it defaults to key.pub.
+	PublicKeyKey string `json:"publicKeyKey,omitempty"`
+}
+
+// PublishedRef references the Secret or ConfigMap the JWT and the public key of a resource are published into.
+type PublishedRef struct {
+	// Kind is the kind of the published resource.
+	Kind PublishKind `json:"kind"`
+	// Name is the name of the published resource.
+	Name string `json:"name"`
+}
+
 // SecretValueFromSource represents the source of a secret value
 type SecretValueFromSource struct {
 	// The Secret key to select from.
 	SecretKeyRef *corev1.SecretKeySelector `json:"secretKeyRef,omitempty"`
 }
+
+// renewAt returns the time at which a JWT with the issue and expiry time is renewed.
+// The JWT is renewed the window before its expiry, by default after two thirds of its validity.
+func renewAt(issuedAt, expiry time.Time, window time.Duration) time.Time {
+	validity := expiry.Sub(issuedAt)
+
+	if window <= 0 {
+		window = validity / 3
+	}
+
+	return expiry.Add(-min(window, validity/2))
+}
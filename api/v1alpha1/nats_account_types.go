@@ -1,11 +1,14 @@
 package v1alpha1
 
 import (
+	"path"
 	"time"
 
+	"github.com/katallaxie/pkg/utilx"
 	"github.com/nats-io/jwt/v2"
 	"github.com/samber/lo"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8slabels "k8s.io/apimachinery/pkg/labels"
 )
 
 type AccountPhase string
@@ -23,6 +26,13 @@ const AnnotationPartOf = "natz.katallaxie.dev/part-of"
 // AnnotationName is an annotation that is used to indicate the name of a resource.
 const AnnotationName = "natz.katallaxie.dev/name"
 
+const (
+	// ConfigMapRevokedUsersKey is the key of the revoked users in the revocations ConfigMap of an account.
+	ConfigMapRevokedUsersKey = "users.json"
+	// ConfigMapRevokedActivationsKey is the key of the revoked activations in the revocations ConfigMap of an account.
+	ConfigMapRevokedActivationsKey = "activations.json"
+)
+
 // NatsAccountReference is a reference to a NatsAccount
 type NatsAccountReference struct {
 	// Name is the name of the account.
@@ -58,6 +68,35 @@ type Export struct {
 	jwt.Info             `json:",inline"`
 }
 
+// AccountImport is an import of a named export of another NatsAccount.
+type AccountImport struct {
+	// Name is the name of the import, it defaults to the name of the export.
+	Name string `json:"name,omitempty"`
+	// AccountRef is a reference to the exporting account.
+	AccountRef NatsAccountReference `json:"accountRef"`
+	// Export is the name of the export of the account.
+	Export string `json:"export"`
+	// LocalSubject is the local subject of the import.
+	LocalSubject jwt.RenamingSubject `json:"local_subject,omitempty"`
+	// Share is a flag that indicates if latency information is shared with the exporting account.
+	Share bool `json:"share,omitempty"`
+	// AllowTrace is a flag that indicates if message tracing is allowed.
+	AllowTrace bool `json:"allow_trace,omitempty"`
+}
+
+// ToNatsJWT returns the import of the export of the account with the public key.
+func (i *AccountImport) ToNatsJWT(account string, export *Export) *jwt.Import {
+	return &jwt.Import{
+		Name:         utilx.Or(i.Name, export.Name),
+		Subject:      export.Subject,
+		Account:      account,
+		LocalSubject: i.LocalSubject,
+		Type:         jwt.ExportType(export.Type),
+		Share:        i.Share,
+		AllowTrace:   i.AllowTrace,
+	}
+}
+
 // OperatorLimits are used to limit access by an account
 type OperatorLimits struct {
 	jwt.NatsLimits            `json:",inline"`
@@ -66,6 +105,29 @@ type OperatorLimits struct {
 	jwt.JetStreamTieredLimits `json:"tiered_limits,omitempty"`
 }
 
+// ScopedSigningKey is a signing key that constrains the users it signs to the permissions and limits of its template.
+type ScopedSigningKey struct {
+	NatsKeyReference `json:",inline"`
+	// Role is the role of the signing key.
+	Role string `json:"role"`
+	// Description is the description of the signing key.
+	Description string `json:"description,omitempty"`
+	// Template are the permissions and limits of the users signed by the key.
+	// Subjects can contain templates, e.g. {{name()}}, {{subject()}}, {{account-name()}} or {{tag(name)}}.
+	Template UserTemplate `json:"template,omitempty"`
+}
+
+// UserScope returns the user scope of the signing key with the public key.
+func (k *ScopedSigningKey) UserScope(publicKey string) *jwt.UserScope {
+	scope := jwt.NewUserScope()
+	scope.Key = publicKey
+	scope.Role = k.Role
+	scope.Description = k.Description
+	scope.Template = k.Template.toNats()
+
+	return scope
+}
+
 // NatsAccountSpec defines the desired state of NatsAccount
 type NatsAccountSpec struct {
 	// SignerKeyRef is the reference to the secret that contains the signing key
@@ -74,16 +136,60 @@ type NatsAccountSpec struct {
 	PrivateKey NatsKeyReference `json:"privateKey,omitempty"`
 	// SigningKeys is a list of references to secrets that contain the signing keys
 	SigningKeys []NatsKeyReference `json:"signingKeys,omitempty"`
+	// ScopedSigningKeys is a list of signing keys that constrain the users they sign.
+	ScopedSigningKeys []ScopedSigningKey `json:"scopedSigningKeys,omitempty"`
 	// OperatorSigningKey is the reference to the operator signing key
 	OperatorSigningKey NatsKeyReference `json:"operatorSigningKey,omitempty"`
-	// Namespaces that are allowed for user creation.
-	// If a NatsUser is referencing this account outside of these namespaces, the operator will create an event for it saying that it's not allowed.
+	// Namespaces that are allowed for user creation, in addition to the namespace of the account.
+	// Namespaces can contain wildcards, e.g. team-* or *.
+	// If a NatsUser is referencing this account outside of these namespaces, the user is forbidden.
 	AllowUserNamespaces []string `json:"allowedUserNamespaces,omitempty"`
+	// AllowUserNamespaceSelector selects the namespaces by label that are allowed for user creation.
+	AllowUserNamespaceSelector *metav1.LabelSelector `json:"allowedUserNamespaceSelector,omitempty"`
+	// AccountImports are imports of exports of other accounts, the public keys and activations are resolved.
+	AccountImports []AccountImport `json:"accountImports,omitempty"`
 	// These fields are directly mappejwtd into the NATS JWT claim
 	Imports     []*jwt.Import      `json:"imports,omitempty"`
 	Exports     []Export           `json:"exports,omitempty"`
 	Limits      OperatorLimits     `json:"limits,omitempty"`
 	Revocations jwt.RevocationList `json:"revocations,omitempty"`
+	// Publish publishes the JWT and the public key of the account into a Secret or ConfigMap.
+	Publish *PublishSpec `json:"publish,omitempty"`
+	// Authorization delegates the authorization of the users of the account to an auth callout service.
+	Authorization ExternalAuthorization `json:"authorization,omitempty"`
+}
+
+// ExternalAuthorization delegates the authorization of the users of an account to an auth callout service.
+type ExternalAuthorization struct {
+	// AuthUsers are the public keys of the users of the auth callout service, they are not authorized by the callout.
+	AuthUsers []string `json:"auth_users,omitempty"`
+	// AllowedAccounts are the public keys of the accounts the auth callout can issue users for, * allows all accounts.
+	AllowedAccounts []string `json:"allowed_accounts,omitempty"`
+	// XKey is the public curve key the authorization requests are encrypted for.
+	XKey string `json:"xkey,omitempty"`
+	// AuthUserKeyRefs are references to the User keys of the users of the auth callout service.
+	AuthUserKeyRefs []NatsKeyReference `json:"authUserKeyRefs,omitempty"`
+	// XKeyRef is a reference to the Curve key the authorization requests are encrypted for.
+	XKeyRef *NatsKeyReference `json:"xkeyRef,omitempty"`
+}
+
+func (a *ExternalAuthorization) toNats() jwt.ExternalAuthorization {
+	return jwt.ExternalAuthorization{
+		AuthUsers:       a.AuthUsers,
+		AllowedAccounts: a.AllowedAccounts,
+		XKey:            a.XKey,
+	}
+}
+
+// FindExport returns the export with the name.
+func (s *NatsAccountSpec) FindExport(name string) (*Export, bool) {
+	for i := range s.Exports {
+		if s.Exports[i].Name == name {
+			return &s.Exports[i], true
+		}
+	}
+
+	return nil, false
 }
 
 func (s *NatsAccountSpec) ToJWTAccount() jwt.Account {
@@ -112,11 +218,62 @@ func (s *NatsAccountSpec) ToJWTAccount() jwt.Account {
 			JetStreamLimits:       s.Limits.JetStreamLimits,
 			JetStreamTieredLimits: s.Limits.JetStreamTieredLimits,
 		},
-		SigningKeys: jwt.SigningKeys{},
-		Revocations: s.Revocations,
+		SigningKeys:   jwt.SigningKeys{},
+		Revocations:   s.Revocations,
+		Authorization: s.Authorization.toNats(),
 	}
 }
 
+// UserRevocation is the revocation of a deleted user.
+type UserRevocation struct {
+	// Name is the namespaced name of the deleted user.
+	Name string `json:"name,omitempty"`
+	// PublicKey is the public key of the revoked user.
+	PublicKey string `json:"publicKey"`
+	// RevokedAt is the timestamp of the revocation, all user JWTs issued before are revoked.
+	RevokedAt metav1.Time `json:"revokedAt"`
+	// Expiry is the expiry of the user JWT, the revocation is pruned afterwards.
+	// A zero expiry keeps the revocation forever.
+	Expiry metav1.Time `json:"expiry,omitempty"`
+}
+
+// ImportedActivation is an activation that is imported into the account.
+type ImportedActivation struct {
+	// Name is the namespaced name of the activation.
+	Name string `json:"name"`
+	// Subject is the imported subject.
+	Subject string `json:"subject"`
+	// Expiry is the expiry of the activation, the import is removed afterwards.
+	Expiry metav1.Time `json:"expiry,omitempty"`
+}
+
+// IssuedActivation is an activation that is issued by the account.
+type IssuedActivation struct {
+	// Name is the namespaced name of the activation.
+	Name string `json:"name"`
+	// Subject is the activated subject.
+	Subject string `json:"subject"`
+	// PublicKey is the public key of the activated account.
+	PublicKey string `json:"publicKey"`
+	// Expiry is the expiry of the activation.
+	Expiry metav1.Time `json:"expiry,omitempty"`
+}
+
+// ActivationRevocation is the revocation of a deleted activation.
+type ActivationRevocation struct {
+	// Name is the namespaced name of the deleted activation.
+	Name string `json:"name,omitempty"`
+	// Subject is the revoked subject.
+	Subject string `json:"subject"`
+	// PublicKey is the public key of the revoked account.
+	PublicKey string `json:"publicKey"`
+	// RevokedAt is the timestamp of the revocation, all activations issued before are revoked.
+	RevokedAt metav1.Time `json:"revokedAt"`
+	// Expiry is the expiry of the activation, the revocation is pruned afterwards.
+	// A zero expiry keeps the revocation forever.
+	Expiry metav1.Time `json:"expiry,omitempty"`
+}
+
 // NatsAccountStatus defines the observed state of NatsAccount
 type NatsAccountStatus struct {
 	// PublicKey is the public key that the account is currently using.
@@ -133,6 +290,16 @@ type NatsAccountStatus struct {
 	ControlPaused bool `json:"controlPaused,omitempty" optional:"true"`
 	// LastUpdate is the timestamp of the last update.
 	LastUpdate metav1.Time `json:"lastUpdate,omitempty"`
+	// RevokedUsers are the users that are revoked in the account JWT.
+	RevokedUsers []UserRevocation `json:"revokedUsers,omitempty"`
+	// ImportedActivations are the activations that are imported into the account JWT.
+	ImportedActivations []ImportedActivation `json:"importedActivations,omitempty"`
+	// Activations are the activations that are issued by the account.
+	Activations []IssuedActivation `json:"activations,omitempty"`
+	// RevokedActivations are the activations that are revoked in the exports of the account JWT.
+	RevokedActivations []ActivationRevocation `json:"revokedActivations,omitempty"`
+	// Published is the Secret or ConfigMap the JWT and the public key of the account are published into.
+	Published *PublishedRef `json:"published,omitempty"`
 }
 
 // +genclient
@@ -174,6 +341,165 @@ func (a *NatsAccount) IsPaused() bool {
 	return a.Status.ControlPaused
 }
 
+// AllowsUserNamespace returns true if users in the namespace with the labels can reference the account.
+func (a *NatsAccount) AllowsUserNamespace(namespace string, labels map[string]string) (bool, error) {
+	if namespace == a.Namespace {
+		return true, nil
+	}
+
+	for _, pattern := range a.Spec.AllowUserNamespaces {
+		ok, err := path.Match(pattern, namespace)
+		if err != nil {
+			return false, err
+		}
+
+		if ok {
+			return true, nil
+		}
+	}
+
+	if a.Spec.AllowUserNamespaceSelector == nil {
+		return false, nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(a.Spec.AllowUserNamespaceSelector)
+	if err != nil {
+		return false, err
+	}
+
+	return selector.Matches(k8slabels.Set(labels)), nil
+}
+
+// RevokeUser adds the revocation of a user.
+// An existing revocation of the public key is replaced.
+func (a *NatsAccount) RevokeUser(revocation UserRevocation) {
+	a.Status.RevokedUsers = lo.Reject(a.Status.RevokedUsers, func(r UserRevocation, _ int) bool {
+		return r.PublicKey == revocation.PublicKey
+	})
+	a.Status.RevokedUsers = append(a.Status.RevokedUsers, revocation)
+}
+
+// RevocationsName returns the name of the ConfigMap that keeps the revocations of the account.
+func (a *NatsAccount) RevocationsName() string {
+	return a.Name + "-revocations"
+}
+
+// RestoreRevocations adds the revocations that are missing in the status, e.g. after the status was lost.
+// Revocations in the status are kept.
+func (a *NatsAccount) RestoreRevocations(users []UserRevocation, activations []ActivationRevocation) {
+	for _, u := range users {
+		if !lo.ContainsBy(a.Status.RevokedUsers, func(r UserRevocation) bool { return r.PublicKey == u.PublicKey }) {
+			a.Status.RevokedUsers = append(a.Status.RevokedUsers, u)
+		}
+	}
+
+	for _, act := range activations {
+		if !lo.ContainsBy(a.Status.RevokedActivations, func(r ActivationRevocation) bool {
+			return r.PublicKey == act.PublicKey && r.Subject == act.Subject
+		}) {
+			a.Status.RevokedActivations = append(a.Status.RevokedActivations, act)
+		}
+	}
+}
+
+// PruneRevokedUsers removes the revocations of users whose JWTs expired before the time.
+func (a *NatsAccount) PruneRevokedUsers(now time.Time) {
+	a.Status.RevokedUsers = lo.Reject(a.Status.RevokedUsers, func(r UserRevocation, _ int) bool {
+		return !r.Expiry.IsZero() && r.Expiry.Time.Before(now)
+	})
+}
+
+// RevokeActivation adds the revocation of an activation.
+// An existing revocation of the subject for the public key is replaced.
+func (a *NatsAccount) RevokeActivation(revocation ActivationRevocation) {
+	a.Status.RevokedActivations = lo.Reject(a.Status.RevokedActivations, func(r ActivationRevocation, _ int) bool {
+		return r.PublicKey == revocation.PublicKey && r.Subject == revocation.Subject
+	})
+	a.Status.RevokedActivations = append(a.Status.RevokedActivations, revocation)
+}
+
+// PruneRevokedActivations removes the revocations of activations that expired before the time.
+func (a *NatsAccount) PruneRevokedActivations(now time.Time) {
+	a.Status.RevokedActivations = lo.Reject(a.Status.RevokedActivations, func(r ActivationRevocation, _ int) bool {
+		return !r.Expiry.IsZero() && r.Expiry.Time.Before(now)
+	})
+}
+
+// NextRevocationExpiry returns the time at which the next user or activation revocation expires.
+// The time is zero if there are no expiring revocations.
+func (a *NatsAccount) NextRevocationExpiry() time.Time {
+	next := time.Time{}
+
+	expiries := lo.Map(a.Status.RevokedUsers, func(r UserRevocation, _ int) metav1.Time { return r.Expiry })
+	expiries = append(expiries, lo.Map(a.Status.RevokedActivations, func(r ActivationRevocation, _ int) metav1.Time { return r.Expiry })...)
+
+	for _, expiry := range expiries {
+		if expiry.IsZero() {
+			continue
+		}
+
+		if next.IsZero() || expiry.Time.Before(next) {
+			next = expiry.Time
+		}
+	}
+
+	return next
+}
+
+// NextImportExpiry returns the time at which the next imported activation expires.
+// The time is zero if there are no expiring activations.
+func (a *NatsAccount) NextImportExpiry() time.Time {
+	next := time.Time{}
+
+	for _, i := range a.Status.ImportedActivations {
+		if i.Expiry.IsZero() {
+			continue
+		}
+
+		if next.IsZero() || i.Expiry.Time.Before(next) {
+			next = i.Expiry.Time
+		}
+	}
+
+	return next
+}
+
+// Revocations returns the revocations of the spec and the revoked users.
+func (a *NatsAccount) Revocations() jwt.RevocationList {
+	revocations := jwt.RevocationList{}
+	for pk, at := range a.Spec.Revocations {
+		revocations[pk] = at
+	}
+
+	for _, r := range a.Status.RevokedUsers {
+		if at, ok := revocations[r.PublicKey]; !ok || at < r.RevokedAt.Unix() {
+			revocations[r.PublicKey] = r.RevokedAt.Unix()
+		}
+	}
+
+	return revocations
+}
+
+// ExportRevocations returns the revocations of the export and the revoked activations of its subject.
+func (a *NatsAccount) ExportRevocations(export *jwt.Export) jwt.RevocationList {
+	revocations := jwt.RevocationList{}
+	for pk, at := range export.Revocations {
+		revocations[pk] = at
+	}
+
+	for _, r := range a.Status.RevokedActivations {
+		if !jwt.Subject(r.Subject).IsContainedIn(export.Subject) {
+			continue
+		}
+
+		if at, ok := revocations[r.PublicKey]; !ok || at < r.RevokedAt.Unix() {
+			revocations[r.PublicKey] = r.RevokedAt.Unix()
+		}
+	}
+
+	return revocations
+}
+
 func init() {
 	SchemeBuilder.Register(&NatsAccount{}, &NatsAccountList{})
 }
@@ -0,0 +1,78 @@
+package v1alpha1_test
+
+import (
+	"testing"
+
+	natsv1alpha1 "github.com/katallaxie/natz-operator/api/v1alpha1"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAllowsUserNamespace(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		spec      natsv1alpha1.NatsAccountSpec
+		namespace string
+		labels    map[string]string
+		expected  bool
+		err       bool
+	}{
+		{name: "same namespace", namespace: "default", expected: true},
+		{name: "other namespace", namespace: "team", expected: false},
+		{name: "allowed namespace", spec: natsv1alpha1.NatsAccountSpec{AllowUserNamespaces: []string{"team"}}, namespace: "team", expected: true},
+		{name: "wildcard", spec: natsv1alpha1.NatsAccountSpec{AllowUserNamespaces: []string{"team-*"}}, namespace: "team-a", expected: true},
+		{name: "wildcard not matching", spec: natsv1alpha1.NatsAccountSpec{AllowUserNamespaces: []string{"team-*"}}, namespace: "other", expected: false},
+		{name: "all namespaces", spec: natsv1alpha1.NatsAccountSpec{AllowUserNamespaces: []string{"*"}}, namespace: "other", expected: true},
+		{name: "invalid pattern", spec: natsv1alpha1.NatsAccountSpec{AllowUserNamespaces: []string{"team-["}}, namespace: "team-a", err: true},
+		{
+			name:      "selector",
+			spec:      natsv1alpha1.NatsAccountSpec{AllowUserNamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"nats": "enabled"}}},
+			namespace: "team",
+			labels:    map[string]string{"nats": "enabled"},
+			expected:  true,
+		},
+		{
+			name:      "selector not matching",
+			spec:      natsv1alpha1.NatsAccountSpec{AllowUserNamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"nats": "enabled"}}},
+			namespace: "team",
+			labels:    map[string]string{"nats": "disabled"},
+			expected:  false,
+		},
+		{
+			name:      "empty selector",
+			spec:      natsv1alpha1.NatsAccountSpec{AllowUserNamespaceSelector: &metav1.LabelSelector{}},
+			namespace: "team",
+			expected:  true,
+		},
+		{
+			name: "invalid selector",
+			spec: natsv1alpha1.NatsAccountSpec{AllowUserNamespaceSelector: &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "nats", Operator: "Unknown"}},
+			}},
+			namespace: "team",
+			err:       true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			account := &natsv1alpha1.NatsAccount{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "account"},
+				Spec:       tc.spec,
+			}
+
+			ok, err := account.AllowsUserNamespace(tc.namespace, tc.labels)
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, ok)
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package v1alpha1
 
 import (
+	"time"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -32,18 +34,38 @@ type NatsActivationSpec struct {
 	TargetAccountRef NatsAccountReference `json:"targetAccountRef"`
 	// Expiry is the expiry time of the activation.
 	Expiry metav1.Time `json:"expiry,omitempty"`
+	// TTL is the validity of the activation (e.g. 30d or 12h), the activation is renewed before it expires.
+	// It can not be used together with an expiry.
+	TTL string `json:"ttl,omitempty"`
+	// RenewBefore is the duration before the expiry at which the activation is renewed.
+	// It defaults to a third of the validity.
+	RenewBefore metav1.Duration `json:"renewBefore,omitempty"`
 	// Start is the start time of the activation.
 	Start metav1.Time `json:"start,omitempty"`
 	// Subject is the subject that the activation is for.
 	Subject string `json:"subject"`
 	// ExportType is the type of export.
 	ExportType ExportType `json:"exportType"`
+	// AutoImport is a flag that indicates if the activation is imported into the target account.
+	// The import is removed when the activation is deleted or expired.
+	AutoImport bool `json:"autoImport,omitempty"`
+	// Publish publishes the JWT and the public key of the activated account into a Secret or ConfigMap.
+	Publish *PublishSpec `json:"publish,omitempty"`
+}
+
+// RenewAt returns the time at which an activation JWT with the issue and expiry time is renewed.
+func (s *NatsActivationSpec) RenewAt(issuedAt, expiry time.Time) time.Time {
+	return renewAt(issuedAt, expiry, s.RenewBefore.Duration)
 }
 
 // NatsActivationStatus defines the observed state of NatsActivation
 type NatsActivationStatus struct {
 	// JWT is the JWT for the user
 	JWT string `json:"jwt,omitempty"`
+	// PublicKey is the public key of the activated account.
+	PublicKey string `json:"publicKey,omitempty"`
+	// RevokedPublicKeys are the public keys of previously activated accounts that are revoked.
+	RevokedPublicKeys []string `json:"revokedPublicKeys,omitempty"`
 	// Conditions is an array of conditions that the operator is currently in.
 	Conditions []metav1.Condition `json:"conditions,omitempty" optional:"true"`
 	// Phase is the current phase of the operator.
@@ -54,6 +76,16 @@ type NatsActivationStatus struct {
 	ControlPaused bool `json:"controlPaused,omitempty" optional:"true"`
 	// LastUpdate is the timestamp of the last update.
 	LastUpdate metav1.Time `json:"lastUpdate,omitempty"`
+	// Expiry is the expiry of the activation JWT.
+	Expiry metav1.Time `json:"expiry,omitempty"`
+	// RenewAt is the time at which the activation JWT is renewed, or reported as about to expire.
+	RenewAt metav1.Time `json:"renewAt,omitempty"`
+	// ExpiringReportedAt is the time at which the activation was reported as about to expire.
+	ExpiringReportedAt metav1.Time `json:"expiringReportedAt,omitempty"`
+	// ObservedGeneration is the generation of the spec the activation JWT is issued for.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Published is the Secret or ConfigMap the JWT and the public key of the activation are published into.
+	Published *PublishedRef `json:"published,omitempty"`
 }
 
 // +genclient
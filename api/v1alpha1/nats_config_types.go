@@ -1,6 +1,11 @@
 package v1alpha1
 
 import (
+	"fmt"
+	"strings"
+
+	"github.com/katallaxie/pkg/cast"
+	"github.com/katallaxie/pkg/utilx"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -11,6 +16,16 @@ const (
 	SecretConfigDataKey = "nats.conf"
 )
 
+// ConfigFormat is the format the config is rendered in.
+type ConfigFormat string
+
+const (
+	// ConfigFormatJSON renders the config as JSON.
+	ConfigFormatJSON ConfigFormat = "json"
+	// ConfigFormatNative renders the config in the NATS configuration format.
+	ConfigFormatNative ConfigFormat = "native"
+)
+
 // New returns a new Config object.
 func New() *Config {
 	return &Config{
@@ -48,6 +63,39 @@ type Config struct {
 	JetStream *JetStream `json:"jetstream,omitempty"`
 }
 
+// ClientAdvertiseURL returns the URL of the client advertise address, it is empty without an address.
+// The listen address is not used, as clients can not connect to it.
+func (c *Config) ClientAdvertiseURL() string {
+	if c.ClientAdvertise == "" || strings.Contains(c.ClientAdvertise, "://") {
+		return c.ClientAdvertise
+	}
+
+	scheme := "nats"
+	if c.TLS != nil {
+		scheme = "tls"
+	}
+
+	return fmt.Sprintf("%s://%s", scheme, c.ClientAdvertise)
+}
+
+// Default applies the defaults of the `default` struct tags to the configuration.
+// Only empty values are defaulted, booleans are pointers so that an explicit false is kept.
+func (c *Config) Default() {
+	utilx.SetDefaults(c)
+
+	if c.Resolver.AllowDelete == nil {
+		c.Resolver.AllowDelete = cast.Ptr(true)
+	}
+
+	if c.JetStream != nil {
+		utilx.SetDefaults(c.JetStream)
+
+		if c.JetStream.Enabled == nil {
+			c.JetStream.Enabled = cast.Ptr(true)
+		}
+	}
+}
+
 // Resolver ...
 type Resolver struct {
 	// Type ...
@@ -55,7 +103,7 @@ type Resolver struct {
 	// Dir ...
 	Dir string `json:"dir,omitempty" default:"/data/resolver"`
 	// AllowDelete ...
-	AllowDelete bool `json:"allow_delete,omitempty" default:"true"`
+	AllowDelete *bool `json:"allow_delete,omitempty"`
 	// Interval ...
 	Interval string `json:"interval,omitempty" default:"2m"`
 	// Limit ...
@@ -70,7 +118,7 @@ type ResolverPreload map[string]string
 // JetStream ...
 type JetStream struct {
 	// Enabled ...
-	Enabled bool `json:"enabled" default:"true"`
+	Enabled *bool `json:"enabled,omitempty"`
 	// StoreDir ...
 	StoreDir string `json:"store_dir" default:"/tmp/nats/jetstream"`
 	// MaxMemoryStore ...
@@ -110,27 +158,27 @@ type JetStreamLimits struct {
 // TLS ...
 type TLS struct {
 	// CertFile ...
-	CertFile string `json:"cert_file"`
+	CertFile string `json:"cert_file,omitempty"`
 	// KeyFile ...
-	KeyFile string `json:"key_file"`
+	KeyFile string `json:"key_file,omitempty"`
 	// CAFile ...
-	CAFile string `json:"ca_file"`
+	CAFile string `json:"ca_file,omitempty"`
 	// CipherSuites ...
-	CipherSuites string `json:"cipher_suites"`
+	CipherSuites string `json:"cipher_suites,omitempty"`
 	// CurvePreferences ...
 	CurvePreferences string `json:"curve_preferences,omitempty"`
 	// Insecure ...
 	Insecure bool `json:"insecure,omitempty"`
 	// Verify ...
-	Verify bool `json:"verify"`
+	Verify bool `json:"verify,omitempty"`
 	// VerifyAndMap ...
-	VerifyAndMap bool `json:"verify_and_map"`
+	VerifyAndMap bool `json:"verify_and_map,omitempty"`
 	// VerifyCertAndCheckKnownURLs ...
 	VerifyCertAndCheckKnownURLs bool `json:"verify_cert_and_check_known_urls,omitempty"`
 	// ConnectionRateLimit ...
 	ConnectionRateLimit int `json:"connection_rate_limit,omitempty"`
 	// PinnedCerts ...
-	PinnedCerts []string `json:"pinned_certs"`
+	PinnedCerts []string `json:"pinned_certs,omitempty"`
 }
 
 // Gateway ...
@@ -140,7 +188,7 @@ type Gateway struct {
 	// RejectUnknownCluster ...
 	RejectUnknownCluster bool `json:"reject_unknown_cluster,omitempty"`
 	// Authorization ...
-	Authorization Authorization `json:"authorization,omitempty"`
+	Authorization *Authorization `json:"authorization,omitempty"`
 	// Host ...
 	Host string `json:"host,omitempty"`
 	// Port ...
@@ -162,16 +210,16 @@ type GatewayEntry struct {
 	// URLS ...
 	URLS []string `json:"urls"`
 	// TLS ...
-	TLS TLS `json:"tls,omitempty"`
+	TLS *TLS `json:"tls,omitempty"`
 }
 
 // Authorization ...
 type Authorization struct {
-	User        string      `json:"user,omitempty"`
-	Password    string      `json:"password,omitempty"`
-	Token       string      `json:"token,omitempty"`
-	Timeout     int         `json:"timeout,omitempty"`
-	AuthCallout AuthCallout `json:"auth_callout,omitempty"`
+	User        string       `json:"user,omitempty"`
+	Password    string       `json:"password,omitempty"`
+	Token       string       `json:"token,omitempty"`
+	Timeout     int          `json:"timeout,omitempty"`
+	AuthCallout *AuthCallout `json:"auth_callout,omitempty"`
 }
 
 // AuthCallout ...
@@ -204,6 +252,35 @@ type NatsConfigSpec struct {
 	Gateways []NatsgatewayReference `json:"gateways,omitempty"`
 	// Config is the configuration that should be applied.
 	Config Config `json:"config,omitempty"`
+	// ClientURLs are the URLs clients connect to, e.g. the URL of the client service of the servers.
+	// They default to the client advertise address of the config.
+	ClientURLs []string `json:"clientURLs,omitempty"`
+	// Format is the format the configuration is rendered in.
+	//
+	// +kubebuilder:validation:Enum={json,native}
+	// +kubebuilder:default=json
+	// +optional
+	Format ConfigFormat `json:"format,omitempty"`
+}
+
+// ServerURLs returns the URLs clients connect to, the client URLs or the client advertise address.
+// It is empty if there is no address that clients can connect to.
+func (s *NatsConfigSpec) ServerURLs() []string {
+	if len(s.ClientURLs) > 0 {
+		return s.ClientURLs
+	}
+
+	if u := s.Config.ClientAdvertiseURL(); u != "" {
+		return []string{u}
+	}
+
+	return nil
+}
+
+// Default applies the defaults to the spec.
+func (s *NatsConfigSpec) Default() {
+	s.Format = utilx.Or(s.Format, ConfigFormatJSON)
+	s.Config.Default()
 }
 
 // NatsConfigStatus defines the observed state of NatsConfig
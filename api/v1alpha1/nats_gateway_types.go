@@ -1,6 +1,7 @@
 package v1alpha1
 
 import (
+	"github.com/katallaxie/pkg/utilx"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -23,12 +24,17 @@ type NatsgatewayReference struct {
 }
 
 type NatsGatewaySpec struct {
+	// Name is the gateway name of the remote cluster, it has to match the name the remote cluster uses for its gateway.
+	// It defaults to the name of the resource.
+	Name string `json:"name,omitempty"`
 	// URL is the URL of the gateway.
 	URL string `json:"url"`
 	// Username is the username of the gateway.
 	Username SecretValueFromSource `json:"username,omitempty"`
 	// Password is the password of the gateway.
 	Password SecretValueFromSource `json:"password,omitempty"`
+	// TLS is the TLS configuration of the connection to the gateway.
+	TLS *TLS `json:"tls,omitempty"`
 }
 
 type NatsGatewayStatus struct {
@@ -70,3 +76,8 @@ type NatsGatewayList struct {
 func init() {
 	SchemeBuilder.Register(&NatsGateway{}, &NatsGatewayList{})
 }
+
+// GatewayName returns the gateway name of the remote cluster.
+func (g *NatsGateway) GatewayName() string {
+	return utilx.Or(g.Spec.Name, g.Name)
+}
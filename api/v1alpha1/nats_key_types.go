@@ -1,7 +1,10 @@
 package v1alpha1
 
 import (
+	"bytes"
 	"errors"
+	"fmt"
+	"time"
 
 	"github.com/nats-io/nkeys"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -28,17 +31,92 @@ const (
 // KeyType is a type that represents the type of the N.
 //
 // +enum
-// +kubebuilder:validation:Enum={Operator,Account,User}
+// +kubebuilder:validation:Enum={Operator,Account,User,Curve,Server,Cluster}
 type KeyType string
 
 const (
 	KeyTypeOperator KeyType = "Operator"
 	KeyTypeAccount  KeyType = "Account"
 	KeyTypeUser     KeyType = "User"
+	// KeyTypeCurve is a curve (xkey) key to encrypt, e.g. the requests of the auth callout.
+	KeyTypeCurve   KeyType = "Curve"
+	KeyTypeServer  KeyType = "Server"
+	KeyTypeCluster KeyType = "Cluster"
 )
 
+// KeyTypes are all supported key types.
+var KeyTypes = []KeyType{KeyTypeOperator, KeyTypeAccount, KeyTypeUser, KeyTypeCurve, KeyTypeServer, KeyTypeCluster}
+
 var ErrUnknownKeyType = errors.New("unknown key type")
 
+// ErrSeedTypeMismatch is returned when an imported seed is not of the type of the key.
+var ErrSeedTypeMismatch = errors.New("seed does not match the key type")
+
+// Prefix returns the prefix byte of the keys of the type.
+func (t KeyType) Prefix() (nkeys.PrefixByte, error) {
+	switch t {
+	case KeyTypeOperator:
+		return nkeys.PrefixByteOperator, nil
+	case KeyTypeAccount:
+		return nkeys.PrefixByteAccount, nil
+	case KeyTypeUser:
+		return nkeys.PrefixByteUser, nil
+	case KeyTypeCurve:
+		return nkeys.PrefixByteCurve, nil
+	case KeyTypeServer:
+		return nkeys.PrefixByteServer, nil
+	case KeyTypeCluster:
+		return nkeys.PrefixByteCluster, nil
+	default:
+		return nkeys.PrefixByteUnknown, ErrUnknownKeyType
+	}
+}
+
+const (
+	// AnnotationRotateKey is the annotation key to trigger a rotation of the key.
+	// Any new value of the annotation triggers a rotation.
+	AnnotationRotateKey = "natz.katallaxie.dev/rotate"
+)
+
+const (
+	// DefaultKeyRotationHistoryLimit is the default number of rotations kept in the status.
+	DefaultKeyRotationHistoryLimit = 10
+)
+
+// KeyRotationReason is the reason of a key rotation.
+type KeyRotationReason string
+
+const (
+	KeyRotationReasonScheduled KeyRotationReason = "Scheduled"
+	KeyRotationReasonManual    KeyRotationReason = "Manual"
+)
+
+// KeyRotation defines the rotation policy of a NATS key.
+type KeyRotation struct {
+	// Interval is the interval after which the key is rotated.
+	// A zero interval disables the scheduled rotation.
+	Interval metav1.Duration `json:"interval,omitempty"`
+	// GracePeriod is the duration the previous seed is kept after a rotation.
+	GracePeriod metav1.Duration `json:"gracePeriod,omitempty"`
+	// HistoryLimit is the number of rotations that are kept in the status.
+	// +kubebuilder:default=10
+	HistoryLimit int `json:"historyLimit,omitempty"`
+}
+
+// KeyRotationRecord is a record of a key rotation.
+type KeyRotationRecord struct {
+	// PublicKey is the public key after the rotation.
+	PublicKey string `json:"publicKey"`
+	// PreviousPublicKey is the public key before the rotation.
+	PreviousPublicKey string `json:"previousPublicKey,omitempty"`
+	// Reason is the reason of the rotation.
+	Reason KeyRotationReason `json:"reason"`
+	// RotatedAt is the timestamp of the rotation.
+	RotatedAt metav1.Time `json:"rotatedAt"`
+	// RetiredAt is the timestamp the previous key was removed after the grace period.
+	RetiredAt metav1.Time `json:"retiredAt,omitempty"`
+}
+
 // NatsReference is a reference to a .
 type NatsReference struct {
 	// Name is the name of the
@@ -57,6 +135,11 @@ type NatsKeySpec struct {
 	// Paused is a flag that indicates if the  is paused.
 	// +kubebuilder:default=false
 	Paused bool `json:"paused,omitempty"`
+	// Rotation is the rotation policy of the key.
+	Rotation *KeyRotation `json:"rotation,omitempty"`
+	// Seed is an existing seed that is imported instead of generating a new key, e.g. a seed created with nsc.
+	// An imported key is rotated by updating the referenced secret.
+	Seed SecretValueFromSource `json:"seed,omitempty"`
 }
 
 // NatsKeyStatus defines the observed state of a NATS key.
@@ -71,6 +154,16 @@ type NatsKeyStatus struct {
 	ControlPaused bool `json:"controlPaused,omitempty" optional:"true"`
 	// LastUpdate is the timestamp of the last update.
 	LastUpdate metav1.Time `json:"lastUpdate,omitempty"`
+	// PublicKey is the current public key.
+	PublicKey string `json:"publicKey,omitempty"`
+	// LastRotation is the timestamp of the last rotation.
+	LastRotation metav1.Time `json:"lastRotation,omitempty"`
+	// LastRotationTrigger is the last handled value of the rotation annotation.
+	LastRotationTrigger string `json:"lastRotationTrigger,omitempty"`
+	// GraceExpiry is the timestamp after which the previous seed is removed.
+	GraceExpiry metav1.Time `json:"graceExpiry,omitempty"`
+	// Rotations is the history of the key rotations.
+	Rotations []KeyRotationRecord `json:"rotations,omitempty"`
 }
 
 // +genclient
@@ -90,21 +183,40 @@ type NatsKey struct {
 
 // Keys returns a pair of keys based on the type of the N.
 func (pk *NatsKey) Keys() (nkeys.KeyPair, error) {
-	var s nkeys.KeyPair
-	var err error
+	prefix, err := pk.Spec.Type.Prefix()
+	if err != nil {
+		return nil, err
+	}
 
-	switch pk.Spec.Type {
-	case KeyTypeOperator:
-		s, err = nkeys.CreateOperator()
-	case KeyTypeAccount:
-		s, err = nkeys.CreateAccount()
-	case KeyTypeUser:
-		s, err = nkeys.CreateUser()
-	default:
-		err = ErrUnknownKeyType
+	return nkeys.CreatePair(prefix)
+}
+
+// IsImported returns true if the seed of the key is imported from a secret.
+func (pk *NatsKey) IsImported() bool {
+	return pk.Spec.Seed.SecretKeyRef != nil && pk.Spec.Seed.SecretKeyRef.Name != ""
+}
+
+// ImportKeys returns the pair of keys of an imported seed.
+// The seed must be of the type of the key, surrounding whitespace is ignored.
+func (pk *NatsKey) ImportKeys(seed []byte) (nkeys.KeyPair, error) {
+	prefix, err := pk.Spec.Type.Prefix()
+	if err != nil {
+		return nil, err
+	}
+
+	seed = bytes.TrimSpace(seed)
+
+	// the seed itself must never be part of an error
+	p, _, err := nkeys.DecodeSeed(seed)
+	if err != nil {
+		return nil, fmt.Errorf("decoding seed: %w", err)
 	}
 
-	return s, err
+	if p != prefix {
+		return nil, fmt.Errorf("%w: %s seed for a %s key", ErrSeedTypeMismatch, p, pk.Spec.Type)
+	}
+
+	return nkeys.FromSeed(seed)
 }
 
 // IsPaused returns true if the private  is paused.
@@ -112,6 +224,51 @@ func (pk *NatsKey) IsPaused() bool {
 	return pk.Spec.Paused
 }
 
+// RotationRequested returns true if a rotation has been requested by the annotation.
+func (pk *NatsKey) RotationRequested() bool {
+	trigger, ok := pk.Annotations[AnnotationRotateKey]
+
+	return ok && trigger != "" && trigger != pk.Status.LastRotationTrigger
+}
+
+// HasRotationPolicy returns true if the key is rotated on a schedule.
+func (pk *NatsKey) HasRotationPolicy() bool {
+	return pk.Spec.Rotation != nil && pk.Spec.Rotation.Interval.Duration > 0
+}
+
+// NextRotation returns the time of the next scheduled rotation.
+// The time is zero if there is no scheduled rotation.
+func (pk *NatsKey) NextRotation() time.Time {
+	if pk.Spec.Rotation == nil || pk.Spec.Rotation.Interval.Duration <= 0 {
+		return time.Time{}
+	}
+
+	last := pk.Status.LastRotation
+	if last.IsZero() {
+		last = pk.CreationTimestamp
+	}
+
+	return last.Add(pk.Spec.Rotation.Interval.Duration)
+}
+
+// GracePeriod returns the duration the previous seed is kept after a rotation.
+func (pk *NatsKey) GracePeriod() time.Duration {
+	if pk.Spec.Rotation == nil {
+		return 0
+	}
+
+	return pk.Spec.Rotation.GracePeriod.Duration
+}
+
+// HistoryLimit returns the number of rotations that are kept in the status.
+func (pk *NatsKey) HistoryLimit() int {
+	if pk.Spec.Rotation == nil || pk.Spec.Rotation.HistoryLimit <= 0 {
+		return DefaultKeyRotationHistoryLimit
+	}
+
+	return pk.Spec.Rotation.HistoryLimit
+}
+
 //+kubebuilder:object:root=true
 
 // NatsKeyList contains a list of NATS keys.
@@ -9,6 +9,10 @@ const (
 	SecretSeedDataKey = "seed.nk"
 	// SecretPublicKeyDataKey ...
 	SecretPublicKeyDataKey = "key.pub"
+	// SecretPreviousSeedDataKey is the key of the previous seed during the grace period of a rotation.
+	SecretPreviousSeedDataKey = "seed.previous.nk"
+	// SecretPreviousPublicKeyDataKey is the key of the previous public key during the grace period of a rotation.
+	SecretPreviousPublicKeyDataKey = "key.previous.pub"
 )
 
 // Phase is a type that represents the current phase of the operator.
@@ -37,14 +41,27 @@ type NatsOperatorSpec struct {
 	PrivateKey NatsKeyReference `json:"privateKey,omitempty"`
 	// EnableSystemAccount is a flag that indicates if the system account should be created.
 	EnableSystemAccount bool `json:"enableSystemAccount,omitempty"`
+	// SystemAccountRef is a reference to the system account of the operator.
+	// It is only used if the system account is enabled.
+	SystemAccountRef NatsAccountReference `json:"systemAccountRef,omitempty"`
 	// SigningKeys is a list of references to secrets that contain the signing keys
 	SigningKeys []NatsKeyReference `json:"signingKeys,omitempty"`
+	// StrictSigningKeyUsage is a flag that indicates if accounts and users must be signed by signing keys.
+	StrictSigningKeyUsage bool `json:"strictSigningKeyUsage,omitempty"`
+	// AccountServerURL is the URL of the account server (e.g. https://host:port/jwt/v1).
+	AccountServerURL string `json:"accountServerURL,omitempty"`
+	// OperatorServiceURLs is a list of NATS URLs (e.g. nats://host:port) which tools can connect to.
+	OperatorServiceURLs []string `json:"operatorServiceURLs,omitempty"`
+	// Tags is a list of tags that are added to the operator.
+	Tags []string `json:"tags,omitempty"`
 	// PreventDeletion is a flag that indicates if the  should be locked to prevent deletion.
 	// +kubebuilder:default=false
 	PreventDeletion bool `json:"prevent_deletion,omitempty"`
 	// Paused is a flag that indicates if the  is paused.
 	// +kubebuilder:default=false
 	Paused bool `json:"paused,omitempty"`
+	// Publish publishes the JWT and the public key of the operator into a Secret or ConfigMap.
+	Publish *PublishSpec `json:"publish,omitempty"`
 }
 
 type NatsOperatorStatus struct {
@@ -62,6 +79,8 @@ type NatsOperatorStatus struct {
 	ControlPaused bool `json:"controlPaused,omitempty" optional:"true"`
 	// LastUpdate is the timestamp of the last update.
 	LastUpdate metav1.Time `json:"lastUpdate,omitempty"`
+	// Published is the Secret or ConfigMap the JWT and the public key of the operator are published into.
+	Published *PublishedRef `json:"published,omitempty"`
 }
 
 // +genclient
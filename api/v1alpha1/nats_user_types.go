@@ -1,6 +1,9 @@
 package v1alpha1
 
 import (
+	"slices"
+	"time"
+
 	"github.com/nats-io/jwt/v2"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -10,8 +13,62 @@ const (
 	SecretUserJWTKey = "user.jwt"
 	// SecretUserCredsKey is the key for the credentials in the secret
 	SecretUserCredsKey = "user.creds"
+	// SecretUserContextKey is the key for the nats CLI context in the secret
+	SecretUserContextKey = "context.json"
+	// SecretUserNKeyKey is the key for the seed of the user in the secret
+	SecretUserNKeyKey = "user.nk"
+	// SecretUserURLKey is the key for the connection URL in the secret
+	SecretUserURLKey = "url"
+	// SecretUserEnvURLKey is the environment variable of the connection URL in the secret
+	SecretUserEnvURLKey = "NATS_URL"
+	// SecretUserEnvCredsKey is the environment variable of the path of the credentials in the secret
+	SecretUserEnvCredsKey = "NATS_CREDS"
+)
+
+const (
+	// TagNamespace is the tag of the namespace of a user bound to a ServiceAccount
+	TagNamespace = "namespace"
+	// TagServiceAccount is the tag of the ServiceAccount of a user bound to a ServiceAccount
+	TagServiceAccount = "serviceaccount"
+	// TagPod is the tag of the pod of a user bound to a ServiceAccount
+	TagPod = "pod"
 )
 
+// CredentialsFormat is a format of the credentials of a user.
+//
+// +enum
+// +kubebuilder:validation:Enum={Creds,Context,Env,NKey,URL}
+type CredentialsFormat string
+
+const (
+	// CredentialsFormatCreds writes the JWT and the creds file, it is always written.
+	CredentialsFormatCreds CredentialsFormat = "Creds"
+	// CredentialsFormatContext writes a nats CLI context.
+	CredentialsFormatContext CredentialsFormat = "Context"
+	// CredentialsFormatEnv writes the NATS_URL and NATS_CREDS environment variables.
+	CredentialsFormatEnv CredentialsFormat = "Env"
+	// CredentialsFormatNKey writes the seed of the user.
+	CredentialsFormatNKey CredentialsFormat = "NKey"
+	// CredentialsFormatURL writes the connection URL of the referenced config.
+	CredentialsFormatURL CredentialsFormat = "URL"
+)
+
+// CredentialsSpec defines the formats of the credentials secret of a user.
+type CredentialsSpec struct {
+	// Formats are the formats that are written to the credentials secret.
+	Formats []CredentialsFormat `json:"formats,omitempty"`
+	// ConfigRef is a reference to the NatsConfig that provides the connection URL.
+	ConfigRef *NatsReference `json:"configRef,omitempty"`
+	// MountPath is the path the credentials secret is mounted at, it is used to reference the credentials file.
+	// +kubebuilder:default=/etc/nats
+	MountPath string `json:"mountPath,omitempty"`
+}
+
+// HasFormat returns true if the format is written to the credentials secret.
+func (s *CredentialsSpec) HasFormat(format CredentialsFormat) bool {
+	return format == CredentialsFormatCreds || slices.Contains(s.Formats, format)
+}
+
 type UserPhase string
 
 const (
@@ -48,12 +105,36 @@ func (p *Permission) toNats() jwt.Permission {
 	}
 }
 
+// UserTemplate are the permissions and limits of users.
+type UserTemplate struct {
+	// Permissions define the permissions for the users
+	Permissions Permissions `json:"permissions,omitempty"`
+	// Limits define the limits for the users
+	Limits Limits `json:"limits,omitempty"`
+	// BearerToken is a flag that indicates if the users should be created with a bearer token
+	BearerToken bool `json:"bearer_token,omitempty"`
+	// AllowedConnectionTypes is a list of allowed connection types
+	AllowedConnectionTypes jwt.StringList `json:"allowed_connection_types,omitempty"`
+}
+
+func (t *UserTemplate) toNats() jwt.UserPermissionLimits {
+	return jwt.UserPermissionLimits{
+		Permissions:            t.Permissions.toNats(),
+		Limits:                 t.Limits.toNats(),
+		BearerToken:            t.BearerToken,
+		AllowedConnectionTypes: t.AllowedConnectionTypes,
+	}
+}
+
 // NatsUserSpec defines the desired state of NatsUser
 type NatsUserSpec struct {
-	// PrivateKey is a reference to a secret that contains the private key
+	// PrivateKey is a reference to a secret that contains the private key.
+	// It is not used by users that are bound to a ServiceAccount, every pod has its own key.
 	PrivateKey NatsKeyReference `json:"privateKey,omitempty"`
-	// SignerKeyRef is a reference to a secret that contains the account signing key
-	SignerKeyRef NatsKeyReference `json:"signerKeyRef"`
+	// SignerKeyRef is a reference to a secret that contains the account signing key.
+	// It defaults to the first scoped signing key of the account, or the account key.
+	// The key is in the namespace of the account, users in other namespaces require a scoped signing key.
+	SignerKeyRef NatsKeyReference `json:"signerKeyRef,omitempty"`
 	// AccountRef is a reference to the account
 	AccountRef NatsReference `json:"accountRef"`
 	// Permissions define the permissions for the user
@@ -64,6 +145,27 @@ type NatsUserSpec struct {
 	BearerToken bool `json:"bearer_token,omitempty"`
 	// AllowedConnectionTypes is a list of allowed connection types
 	AllowedConnectionTypes jwt.StringList `json:"allowed_connection_types,omitempty"`
+	// Credentials defines the formats of the credentials secret.
+	Credentials CredentialsSpec `json:"credentials,omitempty"`
+	// Tags is a list of tags that are added to the user.
+	// They can be used in the templates of scoped signing keys and of the permissions, e.g. {{tag(namespace)}}.
+	Tags jwt.TagList `json:"tags,omitempty"`
+	// ServiceAccountName binds the user to the pods of the ServiceAccount in the namespace of the user.
+	// Every pod using the ServiceAccount is issued its own JWT by the auth callout of the account server,
+	// tagged with the namespace, the ServiceAccount and the name of the pod.
+	// The expiry is required and bounds the validity of the issued JWTs.
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+	// Expiry is the validity (e.g. 30d, 12h) or the expiry time (e.g. 2030-01-01) of the user JWT.
+	// The user JWT does not expire if it is empty.
+	Expiry string `json:"expiry,omitempty"`
+	// RenewBefore is the duration before the expiry at which the user JWT is renewed.
+	// It defaults to a third and is at most half of the validity of the user JWT.
+	RenewBefore metav1.Duration `json:"renewBefore,omitempty"`
+}
+
+// RenewAt returns the time at which a user JWT with the issue and expiry time is renewed.
+func (s *NatsUserSpec) RenewAt(issuedAt, expiry time.Time) time.Time {
+	return renewAt(issuedAt, expiry, s.RenewBefore.Duration)
 }
 
 type UserLimits struct {
@@ -100,6 +202,9 @@ func (s *NatsUserSpec) ToNatsJWT() jwt.User {
 			BearerToken:            s.BearerToken,
 			AllowedConnectionTypes: s.AllowedConnectionTypes,
 		},
+		GenericFields: jwt.GenericFields{
+			Tags: s.Tags,
+		},
 	}
 }
 
@@ -119,6 +224,12 @@ type NatsUserStatus struct {
 	ControlPaused bool `json:"controlPaused,omitempty" optional:"true"`
 	// LastUpdate is the timestamp of the last update.
 	LastUpdate metav1.Time `json:"lastUpdate,omitempty"`
+	// Expiry is the expiry of the user JWT.
+	Expiry metav1.Time `json:"expiry,omitempty"`
+	// RenewAt is the time at which the user JWT is renewed.
+	RenewAt metav1.Time `json:"renewAt,omitempty"`
+	// ObservedGeneration is the generation of the spec the user JWT was issued for.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 }
 
 // +genclient
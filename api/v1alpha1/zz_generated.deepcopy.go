@@ -27,6 +27,39 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AccountImport) DeepCopyInto(out *AccountImport) {
+	*out = *in
+	out.AccountRef = in.AccountRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccountImport.
+func (in *AccountImport) DeepCopy() *AccountImport {
+	if in == nil {
+		return nil
+	}
+	out := new(AccountImport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ActivationRevocation) DeepCopyInto(out *ActivationRevocation) {
+	*out = *in
+	in.RevokedAt.DeepCopyInto(&out.RevokedAt)
+	in.Expiry.DeepCopyInto(&out.Expiry)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ActivationRevocation.
+func (in *ActivationRevocation) DeepCopy() *ActivationRevocation {
+	if in == nil {
+		return nil
+	}
+	out := new(ActivationRevocation)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AuthCallout) DeepCopyInto(out *AuthCallout) {
 	*out = *in
@@ -50,7 +83,11 @@ func (in *AuthCallout) DeepCopy() *AuthCallout {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Authorization) DeepCopyInto(out *Authorization) {
 	*out = *in
-	in.AuthCallout.DeepCopyInto(&out.AuthCallout)
+	if in.AuthCallout != nil {
+		in, out := &in.AuthCallout, &out.AuthCallout
+		*out = new(AuthCallout)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Authorization.
@@ -81,7 +118,7 @@ func (in *Config) DeepCopyInto(out *Config) {
 		*out = new(Authorization)
 		(*in).DeepCopyInto(*out)
 	}
-	out.Resolver = in.Resolver
+	in.Resolver.DeepCopyInto(&out.Resolver)
 	if in.ResolverPreload != nil {
 		in, out := &in.ResolverPreload, &out.ResolverPreload
 		*out = make(ResolverPreload, len(*in))
@@ -92,7 +129,7 @@ func (in *Config) DeepCopyInto(out *Config) {
 	if in.JetStream != nil {
 		in, out := &in.JetStream, &out.JetStream
 		*out = new(JetStream)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 }
 
@@ -106,6 +143,31 @@ func (in *Config) DeepCopy() *Config {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CredentialsSpec) DeepCopyInto(out *CredentialsSpec) {
+	*out = *in
+	if in.Formats != nil {
+		in, out := &in.Formats, &out.Formats
+		*out = make([]CredentialsFormat, len(*in))
+		copy(*out, *in)
+	}
+	if in.ConfigRef != nil {
+		in, out := &in.ConfigRef, &out.ConfigRef
+		*out = new(NatsReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CredentialsSpec.
+func (in *CredentialsSpec) DeepCopy() *CredentialsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CredentialsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Export) DeepCopyInto(out *Export) {
 	*out = *in
@@ -134,10 +196,49 @@ func (in *Export) DeepCopy() *Export {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExternalAuthorization) DeepCopyInto(out *ExternalAuthorization) {
+	*out = *in
+	if in.AuthUsers != nil {
+		in, out := &in.AuthUsers, &out.AuthUsers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedAccounts != nil {
+		in, out := &in.AllowedAccounts, &out.AllowedAccounts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AuthUserKeyRefs != nil {
+		in, out := &in.AuthUserKeyRefs, &out.AuthUserKeyRefs
+		*out = make([]NatsKeyReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.XKeyRef != nil {
+		in, out := &in.XKeyRef, &out.XKeyRef
+		*out = new(NatsKeyReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalAuthorization.
+func (in *ExternalAuthorization) DeepCopy() *ExternalAuthorization {
+	if in == nil {
+		return nil
+	}
+	out := new(ExternalAuthorization)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Gateway) DeepCopyInto(out *Gateway) {
 	*out = *in
-	in.Authorization.DeepCopyInto(&out.Authorization)
+	if in.Authorization != nil {
+		in, out := &in.Authorization, &out.Authorization
+		*out = new(Authorization)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Gateways != nil {
 		in, out := &in.Gateways, &out.Gateways
 		*out = make([]GatewayEntry, len(*in))
@@ -165,7 +266,11 @@ func (in *GatewayEntry) DeepCopyInto(out *GatewayEntry) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
-	in.TLS.DeepCopyInto(&out.TLS)
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(TLS)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewayEntry.
@@ -178,9 +283,46 @@ func (in *GatewayEntry) DeepCopy() *GatewayEntry {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImportedActivation) DeepCopyInto(out *ImportedActivation) {
+	*out = *in
+	in.Expiry.DeepCopyInto(&out.Expiry)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImportedActivation.
+func (in *ImportedActivation) DeepCopy() *ImportedActivation {
+	if in == nil {
+		return nil
+	}
+	out := new(ImportedActivation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IssuedActivation) DeepCopyInto(out *IssuedActivation) {
+	*out = *in
+	in.Expiry.DeepCopyInto(&out.Expiry)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IssuedActivation.
+func (in *IssuedActivation) DeepCopy() *IssuedActivation {
+	if in == nil {
+		return nil
+	}
+	out := new(IssuedActivation)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *JetStream) DeepCopyInto(out *JetStream) {
 	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
 	out.Limits = in.Limits
 }
 
@@ -209,6 +351,40 @@ func (in *JetStreamLimits) DeepCopy() *JetStreamLimits {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeyRotation) DeepCopyInto(out *KeyRotation) {
+	*out = *in
+	out.Interval = in.Interval
+	out.GracePeriod = in.GracePeriod
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeyRotation.
+func (in *KeyRotation) DeepCopy() *KeyRotation {
+	if in == nil {
+		return nil
+	}
+	out := new(KeyRotation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeyRotationRecord) DeepCopyInto(out *KeyRotationRecord) {
+	*out = *in
+	in.RotatedAt.DeepCopyInto(&out.RotatedAt)
+	in.RetiredAt.DeepCopyInto(&out.RetiredAt)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeyRotationRecord.
+func (in *KeyRotationRecord) DeepCopy() *KeyRotationRecord {
+	if in == nil {
+		return nil
+	}
+	out := new(KeyRotationRecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Limits) DeepCopyInto(out *Limits) {
 	*out = *in
@@ -310,12 +486,29 @@ func (in *NatsAccountSpec) DeepCopyInto(out *NatsAccountSpec) {
 		*out = make([]NatsKeyReference, len(*in))
 		copy(*out, *in)
 	}
+	if in.ScopedSigningKeys != nil {
+		in, out := &in.ScopedSigningKeys, &out.ScopedSigningKeys
+		*out = make([]ScopedSigningKey, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	out.OperatorSigningKey = in.OperatorSigningKey
 	if in.AllowUserNamespaces != nil {
 		in, out := &in.AllowUserNamespaces, &out.AllowUserNamespaces
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.AllowUserNamespaceSelector != nil {
+		in, out := &in.AllowUserNamespaceSelector, &out.AllowUserNamespaceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AccountImports != nil {
+		in, out := &in.AccountImports, &out.AccountImports
+		*out = make([]AccountImport, len(*in))
+		copy(*out, *in)
+	}
 	if in.Imports != nil {
 		in, out := &in.Imports, &out.Imports
 		*out = make([]*v2.Import, len(*in))
@@ -342,6 +535,12 @@ func (in *NatsAccountSpec) DeepCopyInto(out *NatsAccountSpec) {
 			(*out)[key] = val
 		}
 	}
+	if in.Publish != nil {
+		in, out := &in.Publish, &out.Publish
+		*out = new(PublishSpec)
+		**out = **in
+	}
+	in.Authorization.DeepCopyInto(&out.Authorization)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NatsAccountSpec.
@@ -365,6 +564,39 @@ func (in *NatsAccountStatus) DeepCopyInto(out *NatsAccountStatus) {
 		}
 	}
 	in.LastUpdate.DeepCopyInto(&out.LastUpdate)
+	if in.RevokedUsers != nil {
+		in, out := &in.RevokedUsers, &out.RevokedUsers
+		*out = make([]UserRevocation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ImportedActivations != nil {
+		in, out := &in.ImportedActivations, &out.ImportedActivations
+		*out = make([]ImportedActivation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Activations != nil {
+		in, out := &in.Activations, &out.Activations
+		*out = make([]IssuedActivation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RevokedActivations != nil {
+		in, out := &in.RevokedActivations, &out.RevokedActivations
+		*out = make([]ActivationRevocation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Published != nil {
+		in, out := &in.Published, &out.Published
+		*out = new(PublishedRef)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NatsAccountStatus.
@@ -458,7 +690,13 @@ func (in *NatsActivationSpec) DeepCopyInto(out *NatsActivationSpec) {
 	out.SignerKeyRef = in.SignerKeyRef
 	out.TargetAccountRef = in.TargetAccountRef
 	in.Expiry.DeepCopyInto(&out.Expiry)
+	out.RenewBefore = in.RenewBefore
 	in.Start.DeepCopyInto(&out.Start)
+	if in.Publish != nil {
+		in, out := &in.Publish, &out.Publish
+		*out = new(PublishSpec)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NatsActivationSpec.
@@ -474,6 +712,11 @@ func (in *NatsActivationSpec) DeepCopy() *NatsActivationSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NatsActivationStatus) DeepCopyInto(out *NatsActivationStatus) {
 	*out = *in
+	if in.RevokedPublicKeys != nil {
+		in, out := &in.RevokedPublicKeys, &out.RevokedPublicKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]metav1.Condition, len(*in))
@@ -482,6 +725,14 @@ func (in *NatsActivationStatus) DeepCopyInto(out *NatsActivationStatus) {
 		}
 	}
 	in.LastUpdate.DeepCopyInto(&out.LastUpdate)
+	in.Expiry.DeepCopyInto(&out.Expiry)
+	in.RenewAt.DeepCopyInto(&out.RenewAt)
+	in.ExpiringReportedAt.DeepCopyInto(&out.ExpiringReportedAt)
+	if in.Published != nil {
+		in, out := &in.Published, &out.Published
+		*out = new(PublishedRef)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NatsActivationStatus.
@@ -564,6 +815,11 @@ func (in *NatsConfigSpec) DeepCopyInto(out *NatsConfigSpec) {
 		copy(*out, *in)
 	}
 	in.Config.DeepCopyInto(&out.Config)
+	if in.ClientURLs != nil {
+		in, out := &in.ClientURLs, &out.ClientURLs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NatsConfigSpec.
@@ -663,6 +919,11 @@ func (in *NatsGatewaySpec) DeepCopyInto(out *NatsGatewaySpec) {
 	*out = *in
 	in.Username.DeepCopyInto(&out.Username)
 	in.Password.DeepCopyInto(&out.Password)
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(TLS)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NatsGatewaySpec.
@@ -703,7 +964,7 @@ func (in *NatsKey) DeepCopyInto(out *NatsKey) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 }
 
@@ -775,6 +1036,12 @@ func (in *NatsKeyReference) DeepCopy() *NatsKeyReference {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NatsKeySpec) DeepCopyInto(out *NatsKeySpec) {
 	*out = *in
+	if in.Rotation != nil {
+		in, out := &in.Rotation, &out.Rotation
+		*out = new(KeyRotation)
+		**out = **in
+	}
+	in.Seed.DeepCopyInto(&out.Seed)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NatsKeySpec.
@@ -798,6 +1065,15 @@ func (in *NatsKeyStatus) DeepCopyInto(out *NatsKeyStatus) {
 		}
 	}
 	in.LastUpdate.DeepCopyInto(&out.LastUpdate)
+	in.LastRotation.DeepCopyInto(&out.LastRotation)
+	in.GraceExpiry.DeepCopyInto(&out.GraceExpiry)
+	if in.Rotations != nil {
+		in, out := &in.Rotations, &out.Rotations
+		*out = make([]KeyRotationRecord, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NatsKeyStatus.
@@ -888,11 +1164,27 @@ func (in *NatsOperatorReference) DeepCopy() *NatsOperatorReference {
 func (in *NatsOperatorSpec) DeepCopyInto(out *NatsOperatorSpec) {
 	*out = *in
 	out.PrivateKey = in.PrivateKey
+	out.SystemAccountRef = in.SystemAccountRef
 	if in.SigningKeys != nil {
 		in, out := &in.SigningKeys, &out.SigningKeys
 		*out = make([]NatsKeyReference, len(*in))
 		copy(*out, *in)
 	}
+	if in.OperatorServiceURLs != nil {
+		in, out := &in.OperatorServiceURLs, &out.OperatorServiceURLs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Publish != nil {
+		in, out := &in.Publish, &out.Publish
+		*out = new(PublishSpec)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NatsOperatorSpec.
@@ -916,6 +1208,11 @@ func (in *NatsOperatorStatus) DeepCopyInto(out *NatsOperatorStatus) {
 		}
 	}
 	in.LastUpdate.DeepCopyInto(&out.LastUpdate)
+	if in.Published != nil {
+		in, out := &in.Published, &out.Published
+		*out = new(PublishedRef)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NatsOperatorStatus.
@@ -1015,6 +1312,13 @@ func (in *NatsUserSpec) DeepCopyInto(out *NatsUserSpec) {
 		*out = make(v2.StringList, len(*in))
 		copy(*out, *in)
 	}
+	in.Credentials.DeepCopyInto(&out.Credentials)
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make(v2.TagList, len(*in))
+		copy(*out, *in)
+	}
+	out.RenewBefore = in.RenewBefore
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NatsUserSpec.
@@ -1038,6 +1342,8 @@ func (in *NatsUserStatus) DeepCopyInto(out *NatsUserStatus) {
 		}
 	}
 	in.LastUpdate.DeepCopyInto(&out.LastUpdate)
+	in.Expiry.DeepCopyInto(&out.Expiry)
+	in.RenewAt.DeepCopyInto(&out.RenewAt)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NatsUserStatus.
@@ -1137,9 +1443,44 @@ func (in *Permissions) DeepCopy() *Permissions {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PublishSpec) DeepCopyInto(out *PublishSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PublishSpec.
+func (in *PublishSpec) DeepCopy() *PublishSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PublishSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PublishedRef) DeepCopyInto(out *PublishedRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PublishedRef.
+func (in *PublishedRef) DeepCopy() *PublishedRef {
+	if in == nil {
+		return nil
+	}
+	out := new(PublishedRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Resolver) DeepCopyInto(out *Resolver) {
 	*out = *in
+	if in.AllowDelete != nil {
+		in, out := &in.AllowDelete, &out.AllowDelete
+		*out = new(bool)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Resolver.
@@ -1173,6 +1514,23 @@ func (in ResolverPreload) DeepCopy() ResolverPreload {
 	return *out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScopedSigningKey) DeepCopyInto(out *ScopedSigningKey) {
+	*out = *in
+	out.NatsKeyReference = in.NatsKeyReference
+	in.Template.DeepCopyInto(&out.Template)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScopedSigningKey.
+func (in *ScopedSigningKey) DeepCopy() *ScopedSigningKey {
+	if in == nil {
+		return nil
+	}
+	out := new(ScopedSigningKey)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SecretValueFromSource) DeepCopyInto(out *SecretValueFromSource) {
 	*out = *in
@@ -1237,3 +1595,42 @@ func (in *UserLimits) DeepCopy() *UserLimits {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UserRevocation) DeepCopyInto(out *UserRevocation) {
+	*out = *in
+	in.RevokedAt.DeepCopyInto(&out.RevokedAt)
+	in.Expiry.DeepCopyInto(&out.Expiry)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UserRevocation.
+func (in *UserRevocation) DeepCopy() *UserRevocation {
+	if in == nil {
+		return nil
+	}
+	out := new(UserRevocation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UserTemplate) DeepCopyInto(out *UserTemplate) {
+	*out = *in
+	in.Permissions.DeepCopyInto(&out.Permissions)
+	in.Limits.DeepCopyInto(&out.Limits)
+	if in.AllowedConnectionTypes != nil {
+		in, out := &in.AllowedConnectionTypes, &out.AllowedConnectionTypes
+		*out = make(v2.StringList, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UserTemplate.
+func (in *UserTemplate) DeepCopy() *UserTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(UserTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -5,6 +5,8 @@ import (
 	"crypto/tls"
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	natzv1alpha1 "github.com/katallaxie/natz-operator/api/v1alpha1"
 	"github.com/katallaxie/natz-operator/controllers"
@@ -15,6 +17,7 @@ import (
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/server"
@@ -34,6 +37,15 @@ type flags struct {
 	probeAddr            string
 	secureMetrics        bool
 	enableHTTP2          bool
+	resolverAddr         string
+	resolverMaxAge       time.Duration
+	operatorName         string
+	operatorNamespace    string
+	authCalloutIssuer    string
+	authCalloutXKey      string
+	authCalloutAudiences []string
+	authCalloutTTL       time.Duration
+	authCalloutCreds     string
 }
 
 var f = &flags{}
@@ -57,6 +69,15 @@ func init() {
 	rootCmd.Flags().StringVar(&f.probeAddr, "health-probe-bind-address", ":8081", "health probe")
 	rootCmd.Flags().BoolVar(&f.secureMetrics, "secure-metrics", f.secureMetrics, "serve metrics over https")
 	rootCmd.Flags().BoolVar(&f.enableHTTP2, "enable-http2", f.enableHTTP2, "enable http/2")
+	rootCmd.Flags().StringVar(&f.resolverAddr, "resolver-bind-address", ":9090", "url resolver endpoint, empty to disable")
+	rootCmd.Flags().DurationVar(&f.resolverMaxAge, "resolver-cache-max-age", f.resolverMaxAge, "max age of cached jwts")
+	rootCmd.Flags().StringVar(&f.operatorName, "operator-name", f.operatorName, "name of the served operator")
+	rootCmd.Flags().StringVar(&f.operatorNamespace, "operator-namespace", os.Getenv("POD_NAMESPACE"), "namespace of the served operator")
+	rootCmd.Flags().StringVar(&f.authCalloutIssuer, "auth-callout-issuer", f.authCalloutIssuer, "[namespace/]name of the key that signs the auth callout responses, empty to disable")
+	rootCmd.Flags().StringVar(&f.authCalloutXKey, "auth-callout-xkey", f.authCalloutXKey, "[namespace/]name of the curve key that encrypts the auth callout")
+	rootCmd.Flags().StringSliceVar(&f.authCalloutAudiences, "auth-callout-audiences", []string{controllers.DefaultAuthCalloutAudience}, "audiences of the service account tokens, must not be empty")
+	rootCmd.Flags().StringVar(&f.authCalloutCreds, "auth-callout-creds", f.authCalloutCreds, "credentials file of the auth callout user, defaults to the credentials of the account server")
+	rootCmd.Flags().DurationVar(&f.authCalloutTTL, "auth-callout-ttl", controllers.DefaultAuthCalloutTTL, "validity of the user jwts issued by the auth callout")
 
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 	utilruntime.Must(natzv1alpha1.AddToScheme(scheme))
@@ -114,6 +135,37 @@ func run(ctx context.Context) error {
 		return err
 	}
 
+	if f.resolverAddr != "" {
+		operator := client.ObjectKey{Namespace: f.operatorNamespace, Name: f.operatorName}
+		r := newResolver(ac, mgr.GetClient(), operator, f.resolverAddr, f.resolverMaxAge)
+
+		err = mgr.Add(r)
+		if err != nil {
+			return err
+		}
+	}
+
+	if f.authCalloutIssuer != "" {
+		namespace := os.Getenv("POD_NAMESPACE")
+		issuer := objectKey(f.authCalloutIssuer, namespace)
+		xkey := objectKey(f.authCalloutXKey, namespace)
+
+		cc := nc
+		if f.authCalloutCreds != "" {
+			cc, err = nats.Connect(os.Getenv("NATS_URL"), nats.UserCredentials(f.authCalloutCreds))
+			if err != nil {
+				return err
+			}
+			defer cc.Drain() //nolint:errcheck
+			defer cc.Close()
+		}
+
+		callout := controllers.NewNatsAuthCallout(mgr, cc, issuer, xkey, f.authCalloutTTL, f.authCalloutAudiences...)
+		if err := callout.SetupWithManager(mgr); err != nil {
+			return err
+		}
+	}
+
 	//+kubebuilder:scaffold:builders
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -134,6 +186,15 @@ func run(ctx context.Context) error {
 	return nil
 }
 
+// objectKey parses a [namespace/]name reference, the namespace defaults to the given namespace.
+func objectKey(ref, namespace string) client.ObjectKey {
+	if ns, name, ok := strings.Cut(ref, "/"); ok {
+		return client.ObjectKey{Namespace: ns, Name: name}
+	}
+
+	return client.ObjectKey{Namespace: namespace, Name: ref}
+}
+
 func main() {
 	if err := rootCmd.Execute(); err != nil {
 		setupLog.Error(err, "unable to run operator")
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	natzv1alpha1 "github.com/katallaxie/natz-operator/api/v1alpha1"
+	"github.com/katallaxie/natz-operator/controllers"
+
+	"github.com/nats-io/nkeys"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// contentTypeJWT is the content type of the served JWTs.
+	contentTypeJWT = "application/jwt"
+	// shutdownTimeout is the time to wait for open requests on shutdown.
+	shutdownTimeout = 5 * time.Second
+)
+
+// resolver serves the account and operator JWTs via the NATS account server protocol.
+// It is served by every replica from the shared informer cache.
+type resolver struct {
+	server   *controllers.NatsAccountServer
+	reader   client.Reader
+	operator client.ObjectKey
+	addr     string
+	maxAge   time.Duration
+}
+
+// newResolver ...
+func newResolver(server *controllers.NatsAccountServer, reader client.Reader, operator client.ObjectKey, addr string, maxAge time.Duration) *resolver {
+	return &resolver{
+		server:   server,
+		reader:   reader,
+		operator: operator,
+		addr:     addr,
+		maxAge:   maxAge,
+	}
+}
+
+// NeedLeaderElection returns false, all replicas behind the service serve the JWTs.
+func (r *resolver) NeedLeaderElection() bool {
+	return false
+}
+
+// Handler returns the HTTP handler of the resolver.
+func (r *resolver) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /jwt/v1/accounts/{pubkey}", r.handleAccount)
+	mux.HandleFunc("GET /jwt/v1/operator", r.handleOperator)
+	mux.HandleFunc("GET /healthz", r.handleHealth)
+
+	return mux
+}
+
+// Start serves the resolver on its address until the context is done.
+func (r *resolver) Start(ctx context.Context) error {
+	srv := &http.Server{
+		Addr:              r.addr,
+		Handler:           r.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(_ net.Listener) context.Context { return ctx },
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	sctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), shutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(sctx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
+
+func (r *resolver) handleAccount(w http.ResponseWriter, req *http.Request) {
+	pubkey := req.PathValue("pubkey")
+	if !nkeys.IsValidPublicAccountKey(pubkey) {
+		http.Error(w, "invalid account public key", http.StatusBadRequest)
+		return
+	}
+
+	token, ok, err := r.server.GetJWT(req.Context(), pubkey)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if !ok {
+		http.Error(w, "account not found", http.StatusNotFound)
+		return
+	}
+
+	r.serveJWT(w, req, token)
+}
+
+func (r *resolver) handleOperator(w http.ResponseWriter, req *http.Request) {
+	if r.operator.Name == "" {
+		http.Error(w, "operator not configured", http.StatusNotFound)
+		return
+	}
+
+	operator := &natzv1alpha1.NatsOperator{}
+	if err := r.reader.Get(req.Context(), r.operator, operator); err != nil {
+		if apierrors.IsNotFound(err) {
+			http.Error(w, "operator not found", http.StatusNotFound)
+			return
+		}
+
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	if operator.Status.JWT == "" {
+		http.Error(w, "operator not synchronized", http.StatusNotFound)
+		return
+	}
+
+	r.serveJWT(w, req, operator.Status.JWT)
+}
+
+func (r *resolver) handleHealth(w http.ResponseWriter, _ *http.Request) {
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte("ok"))
+}
+
+// serveJWT writes the token with caching headers.
+// Requests with a matching If-None-Match header are answered with 304 Not Modified.
+func (r *resolver) serveJWT(w http.ResponseWriter, req *http.Request, token string) {
+	sum := sha256.Sum256([]byte(token))
+	etag := fmt.Sprintf("%q", hex.EncodeToString(sum[:]))
+
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", r.cacheControl())
+
+	if matchETag(req.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentTypeJWT)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(token))
+}
+
+func (r *resolver) cacheControl() string {
+	if r.maxAge <= 0 {
+		return "no-cache"
+	}
+
+	return fmt.Sprintf("public, max-age=%d", int(r.maxAge.Seconds()))
+}
+
+// matchETag checks if the etag is matched by the If-None-Match header.
+func matchETag(header, etag string) bool {
+	for _, match := range strings.Split(header, ",") {
+		match = strings.TrimPrefix(strings.TrimSpace(match), "W/")
+		if match == etag || match == "*" {
+			return true
+		}
+	}
+
+	return false
+}
@@ -7,6 +7,7 @@ import (
 
 	natzv1alpha1 "github.com/katallaxie/natz-operator/api/v1alpha1"
 	"github.com/katallaxie/natz-operator/controllers"
+	"github.com/katallaxie/natz-operator/webhooks"
 	"github.com/spf13/cobra"
 
 	"k8s.io/apimachinery/pkg/runtime"
@@ -16,6 +17,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 )
 
 var (
@@ -32,6 +34,9 @@ type flags struct {
 	probeAddr            string
 	secureMetrics        bool
 	enableHTTP2          bool
+	enableWebhooks       bool
+	webhookPort          int
+	webhookCertDir       string
 }
 
 var f = &flags{}
@@ -55,6 +60,9 @@ func init() {
 	rootCmd.Flags().StringVar(&f.probeAddr, "health-probe-bind-address", ":8081", "health probe")
 	rootCmd.Flags().BoolVar(&f.secureMetrics, "secure-metrics", f.secureMetrics, "serve metrics over https")
 	rootCmd.Flags().BoolVar(&f.enableHTTP2, "enable-http2", f.enableHTTP2, "enable http/2")
+	rootCmd.Flags().BoolVar(&f.enableWebhooks, "enable-webhooks", f.enableWebhooks, "enable admission webhooks")
+	rootCmd.Flags().IntVar(&f.webhookPort, "webhook-port", webhook.DefaultPort, "admission webhook port")
+	rootCmd.Flags().StringVar(&f.webhookCertDir, "webhook-cert-dir", "", "directory of the admission webhook certificate")
 
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 
@@ -92,6 +100,11 @@ func run(ctx context.Context) error {
 			SecureServing: f.secureMetrics,
 			TLSOpts:       tlsOpts,
 		},
+		WebhookServer: webhook.NewServer(webhook.Options{
+			Port:    f.webhookPort,
+			CertDir: f.webhookCertDir,
+			TLSOpts: tlsOpts,
+		}),
 		HealthProbeBindAddress: f.probeAddr,
 		LeaderElection:         f.enableLeaderElection,
 		LeaderElectionID:       "432c802.katallaxie.dev",
@@ -117,6 +130,13 @@ func run(ctx context.Context) error {
 		return err
 	}
 
+	if f.enableWebhooks {
+		err = webhooks.SetupWithManager(mgr)
+		if err != nil {
+			return err
+		}
+	}
+
 	//+kubebuilder:scaffold:builders
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -0,0 +1,54 @@
+package controllers
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"github.com/nats-io/jwt/v2"
+)
+
+// reuseToken returns the previous token if it carries the same claims as the next token.
+// This prevents re-issuing tokens which only differ by their issue time and id.
+func reuseToken(prev, next string) string {
+	if prev == "" {
+		return next
+	}
+
+	p, err := claimsOf(prev)
+	if err != nil {
+		return next
+	}
+
+	n, err := claimsOf(next)
+	if err != nil {
+		return next
+	}
+
+	if reflect.DeepEqual(p, n) {
+		return prev
+	}
+
+	return next
+}
+
+func claimsOf(token string) (map[string]any, error) {
+	claims, err := jwt.Decode(token)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := json.Marshal(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	m := map[string]any{}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+
+	delete(m, "iat")
+	delete(m, "jti")
+
+	return m, nil
+}
@@ -0,0 +1,112 @@
+package controllers
+
+import (
+	"context"
+	"strconv"
+
+	natsv1alpha1 "github.com/katallaxie/natz-operator/api/v1alpha1"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// keyRefIndex is the field index of the NATS keys referenced by a resource.
+	keyRefIndex = ".spec.keyRefs"
+	// accountRefIndex is the field index of the NATS accounts referenced by a resource.
+	accountRefIndex = ".spec.accountRefs"
+	// operatorRefIndex is the field index of the NATS operators referenced by a resource.
+	operatorRefIndex = ".spec.operatorRefs"
+	// gatewayRefIndex is the field index of the NATS gateways referenced by a resource.
+	gatewayRefIndex = ".spec.gatewayRefs"
+	// configRefIndex is the field index of the NATS configs referenced by a resource.
+	configRefIndex = ".spec.configRefs"
+	// secretRefIndex is the field index of the secrets referenced by a resource.
+	secretRefIndex = ".spec.secretRefs"
+	// accountPublicKeyIndex is the field index of the public key of a NATS account.
+	accountPublicKeyIndex = ".status.publicKey"
+)
+
+// indexRef returns the index value of a referenced object.
+func indexRef(namespace, name string) string {
+	return client.ObjectKey{Namespace: namespace, Name: name}.String()
+}
+
+// enqueueReferencing returns a map function that enqueues all objects of the list type
+// which are referencing the object by the given field index.
+func enqueueReferencing(c client.Client, list client.ObjectList, index string) handler.MapFunc {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		l, ok := list.DeepCopyObject().(client.ObjectList)
+		if !ok {
+			return nil
+		}
+
+		if err := c.List(ctx, l, client.MatchingFields{index: client.ObjectKeyFromObject(obj).String()}); err != nil {
+			log.FromContext(ctx).Error(err, "listing referencing objects", "index", index)
+			return nil
+		}
+
+		items, err := meta.ExtractList(l)
+		if err != nil {
+			return nil
+		}
+
+		requests := make([]reconcile.Request, 0, len(items))
+		for _, item := range items {
+			o, ok := item.(client.Object)
+			if !ok {
+				continue
+			}
+
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(o)})
+		}
+
+		return requests
+	}
+}
+
+// valueChanged returns a predicate that only passes updates which change the value of the object.
+// This is used to only cascade changes of the signed JWTs and public keys to dependent resources.
+func valueChanged[T client.Object](value func(T) string) predicate.Funcs {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			o, ok := e.ObjectOld.(T)
+			if !ok {
+				return false
+			}
+
+			n, ok := e.ObjectNew.(T)
+			if !ok {
+				return false
+			}
+
+			return value(o) != value(n)
+		},
+	}
+}
+
+// accountChanged passes updates of the public key or JWT of an account.
+func accountChanged() predicate.Funcs {
+	return valueChanged(func(a *natsv1alpha1.NatsAccount) string {
+		return a.Status.PublicKey + a.Status.JWT
+	})
+}
+
+// operatorChanged passes updates of the JWT of an operator.
+func operatorChanged() predicate.Funcs {
+	return valueChanged(func(o *natsv1alpha1.NatsOperator) string {
+		return o.Status.JWT
+	})
+}
+
+// activationChanged passes updates of the JWT or the import of an activation.
+func activationChanged() predicate.Funcs {
+	return valueChanged(func(a *natsv1alpha1.NatsActivation) string {
+		return a.Status.JWT + strconv.FormatBool(a.Spec.AutoImport)
+	})
+}
@@ -2,7 +2,10 @@ package controllers
 
 import (
 	"context"
+	goerrors "errors"
+	"fmt"
 	"math"
+	"sort"
 	"time"
 
 	natsv1alpha1 "github.com/katallaxie/natz-operator/api/v1alpha1"
@@ -15,11 +18,14 @@ import (
 	"github.com/nats-io/nkeys"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
@@ -39,6 +45,9 @@ const (
 	EventReasonAccountAccessFailed          EventReason = "AccountAccessFailed"
 )
 
+// ErrExportNotFound is returned when an imported export does not exist.
+var ErrExportNotFound = goerrors.New("export not found")
+
 // NatsAccountReconciler ...
 type NatsAccountReconciler struct {
 	client.Client
@@ -99,7 +108,10 @@ func (r *NatsAccountReconciler) reconcileResources(ctx context.Context, account
 		return err
 	}
 
-	return nil
+	published, err := publish(ctx, r.Client, r.Scheme, account, account.Spec.Publish, account.Status.Published, "account", account.Status.JWT, account.Status.PublicKey)
+	account.Status.Published = published
+
+	return err
 }
 
 //nolint:gocyclo
@@ -163,40 +175,294 @@ func (r *NatsAccountReconciler) reconcileAccount(ctx context.Context, account *n
 	token.Name = account.Name
 	token.Account = account.Spec.ToJWTAccount()
 
+	if err := syncRevocations(ctx, r.Client, account, time.Now()); err != nil {
+		return err
+	}
+
+	token.Revocations = account.Revocations()
+
+	for _, export := range token.Exports {
+		export.Revocations = account.ExportRevocations(export)
+	}
+
+	imports, err := r.accountImports(ctx, account)
+	meta.SetStatusCondition(&account.Status.Conditions, status.NewAccountImportedCondition(account, err))
+	if err != nil {
+		return err
+	}
+	token.Imports.Add(imports...)
+
+	activations, err := r.activations(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	imported, importedActivations, err := activationImports(account, public, activations, token.Imports)
+	if err != nil {
+		return err
+	}
+	token.Imports.Add(imported...)
+	account.Status.ImportedActivations = importedActivations
+
+	account.Status.Activations, err = issuedActivations(account, activations)
+	if err != nil {
+		return err
+	}
+
 	for _, key := range account.Spec.SigningKeys {
-		sk := &corev1.Secret{}
-		skName := client.ObjectKey{
-			Namespace: account.Namespace,
-			Name:      key.Name,
+		keys, err := r.signingKeys(ctx, account.Namespace, key.Name)
+		if err != nil {
+			return err
 		}
 
-		if err := r.Get(ctx, skName, sk); err != nil {
+		token.SigningKeys.Add(keys...)
+	}
+
+	for _, key := range account.Spec.ScopedSigningKeys {
+		keys, err := r.signingKeys(ctx, account.Namespace, key.Name)
+		if err != nil {
 			return err
 		}
 
-		skSigner, err := nkeys.FromSeed(sk.Data[natsv1alpha1.SecretSeedDataKey])
+		for _, k := range keys {
+			token.SigningKeys.AddScopedSigner(key.UserScope(k))
+		}
+	}
+
+	for _, key := range account.Spec.Authorization.AuthUserKeyRefs {
+		keys, err := r.signingKeys(ctx, account.Namespace, key.Name)
 		if err != nil {
 			return err
 		}
 
-		pkSigner, err := skSigner.PublicKey()
+		token.Authorization.AuthUsers.Add(keys...)
+	}
+
+	if ref := account.Spec.Authorization.XKeyRef; ref != nil {
+		keys, err := r.signingKeys(ctx, account.Namespace, ref.Name)
 		if err != nil {
 			return err
 		}
 
-		token.SigningKeys.Add(pkSigner)
+		// the requests are only encrypted for the current key
+		token.Authorization.XKey = keys[0]
 	}
 
 	t, err := token.Encode(signerKp)
 	if err != nil {
 		return err
 	}
-	account.Status.JWT = t
-	account.Status.PublicKey = public
+
+	if signed := reuseToken(account.Status.JWT, t); signed != account.Status.JWT || account.Status.PublicKey != public {
+		account.Status.JWT = signed
+		account.Status.PublicKey = public
+		account.Status.LastUpdate = metav1.Now()
+	}
 
 	return nil
 }
 
+// accountImports resolves the imports of exports of other accounts.
+func (r *NatsAccountReconciler) accountImports(ctx context.Context, account *natsv1alpha1.NatsAccount) ([]*jwt.Import, error) {
+	imports := []*jwt.Import{}
+
+	for _, imp := range account.Spec.AccountImports {
+		exporter := &natsv1alpha1.NatsAccount{}
+		exporterName := client.ObjectKey{
+			Namespace: utilx.Or(imp.AccountRef.Namespace, account.Namespace),
+			Name:      imp.AccountRef.Name,
+		}
+
+		if err := r.Get(ctx, exporterName, exporter); err != nil {
+			return nil, err
+		}
+
+		export, ok := exporter.Spec.FindExport(imp.Export)
+		if !ok {
+			return nil, fmt.Errorf("%w: account %s has no export %s", ErrExportNotFound, exporterName, imp.Export)
+		}
+
+		if exporter.Status.PublicKey == "" {
+			return nil, fmt.Errorf("account %s is not synchronized", exporterName)
+		}
+
+		i := imp.ToNatsJWT(exporter.Status.PublicKey, export)
+
+		// private exports require an activation of the exporting account
+		if export.TokenReq {
+			token, err := r.activationToken(ctx, account, exporter, export)
+			if err != nil {
+				return nil, err
+			}
+			i.Token = token
+		}
+
+		imports = append(imports, i)
+	}
+
+	return imports, nil
+}
+
+// activations returns the activations that are issued by or for the account.
+func (r *NatsAccountReconciler) activations(ctx context.Context, account *natsv1alpha1.NatsAccount) ([]natsv1alpha1.NatsActivation, error) {
+	activations := &natsv1alpha1.NatsActivationList{}
+	if err := r.List(ctx, activations, client.MatchingFields{accountRefIndex: indexRef(account.Namespace, account.Name)}); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(activations.Items, func(i, j int) bool {
+		return client.ObjectKeyFromObject(&activations.Items[i]).String() < client.ObjectKeyFromObject(&activations.Items[j]).String()
+	})
+
+	return activations.Items, nil
+}
+
+// issuedActivations returns the active activations that are issued by the account.
+func issuedActivations(account *natsv1alpha1.NatsAccount, activations []natsv1alpha1.NatsActivation) ([]natsv1alpha1.IssuedActivation, error) {
+	issued := []natsv1alpha1.IssuedActivation{}
+
+	for _, activation := range activations {
+		if activation.Namespace != account.Namespace || activation.Spec.AccountRef.Name != account.Name {
+			continue
+		}
+
+		if activation.Status.JWT == "" || !activation.DeletionTimestamp.IsZero() {
+			continue
+		}
+
+		claims, err := jwt.DecodeActivationClaims(activation.Status.JWT)
+		if err != nil {
+			return nil, err
+		}
+
+		if claims.Expires > 0 && time.Now().Unix() >= claims.Expires {
+			continue
+		}
+
+		i := natsv1alpha1.IssuedActivation{
+			Name:      client.ObjectKeyFromObject(&activation).String(),
+			Subject:   string(claims.ImportSubject),
+			PublicKey: claims.Subject,
+		}
+		if claims.Expires > 0 {
+			i.Expiry = metav1.NewTime(time.Unix(claims.Expires, 0))
+		}
+		issued = append(issued, i)
+	}
+
+	return issued, nil
+}
+
+// activationImports returns the imports of the activations that are imported into the account.
+// Deleted and expired activations, and activations that are already imported, are skipped.
+func activationImports(account *natsv1alpha1.NatsAccount, public string, activations []natsv1alpha1.NatsActivation, existing jwt.Imports) ([]*jwt.Import, []natsv1alpha1.ImportedActivation, error) {
+	imports := []*jwt.Import{}
+	imported := []natsv1alpha1.ImportedActivation{}
+
+	for _, activation := range activations {
+		target := client.ObjectKey{
+			Namespace: utilx.Or(activation.Spec.TargetAccountRef.Namespace, activation.Namespace),
+			Name:      activation.Spec.TargetAccountRef.Name,
+		}
+
+		if !activation.Spec.AutoImport || target != client.ObjectKeyFromObject(account) || activation.Status.JWT == "" || !activation.DeletionTimestamp.IsZero() {
+			continue
+		}
+
+		claims, err := jwt.DecodeActivationClaims(activation.Status.JWT)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// the activation is re-issued when the key of the account changed
+		if claims.Subject != public || (claims.Expires > 0 && time.Now().Unix() >= claims.Expires) {
+			continue
+		}
+
+		imp := &jwt.Import{
+			Name:    activation.Name,
+			Subject: claims.ImportSubject,
+			Account: utilx.Or(claims.IssuerAccount, claims.Issuer),
+			Token:   activation.Status.JWT,
+			Type:    claims.ImportType,
+		}
+
+		if slices.Any(func(i *jwt.Import) bool { return i.Account == imp.Account && i.Subject == imp.Subject }, existing...) {
+			continue
+		}
+
+		imports = append(imports, imp)
+
+		i := natsv1alpha1.ImportedActivation{
+			Name:    client.ObjectKeyFromObject(&activation).String(),
+			Subject: string(claims.ImportSubject),
+		}
+		if claims.Expires > 0 {
+			i.Expiry = metav1.NewTime(time.Unix(claims.Expires, 0))
+		}
+		imported = append(imported, i)
+	}
+
+	return imports, imported, nil
+}
+
+// activationToken returns the activation token of the export for the account.
+func (r *NatsAccountReconciler) activationToken(ctx context.Context, account, exporter *natsv1alpha1.NatsAccount, export *natsv1alpha1.Export) (string, error) {
+	activations := &natsv1alpha1.NatsActivationList{}
+	if err := r.List(ctx, activations, client.InNamespace(exporter.Namespace)); err != nil {
+		return "", err
+	}
+
+	for _, activation := range activations.Items {
+		target := client.ObjectKey{
+			Namespace: utilx.Or(activation.Spec.TargetAccountRef.Namespace, activation.Namespace),
+			Name:      activation.Spec.TargetAccountRef.Name,
+		}
+
+		if activation.Spec.AccountRef.Name != exporter.Name || target != client.ObjectKeyFromObject(account) {
+			continue
+		}
+
+		if activation.Spec.Subject != string(export.Subject) || activation.Spec.ExportType != export.Type {
+			continue
+		}
+
+		if activation.Status.JWT == "" || !activation.DeletionTimestamp.IsZero() {
+			continue
+		}
+
+		return activation.Status.JWT, nil
+	}
+
+	return "", fmt.Errorf("no activation of export %s of account %s for account %s", export.Name, exporter.Name, account.Name)
+}
+
+// signingKeys returns the public keys of a signing key,
+// the previous signing key is still trusted during the grace period of a rotation.
+func (r *NatsAccountReconciler) signingKeys(ctx context.Context, namespace, name string) ([]string, error) {
+	sk := &corev1.Secret{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, sk); err != nil {
+		return nil, err
+	}
+
+	skSigner, err := nkeys.FromSeed(sk.Data[natsv1alpha1.SecretSeedDataKey])
+	if err != nil {
+		return nil, err
+	}
+
+	pkSigner, err := skSigner.PublicKey()
+	if err != nil {
+		return nil, err
+	}
+
+	keys := []string{pkSigner}
+	if previous, ok := sk.Data[natsv1alpha1.SecretPreviousPublicKeyDataKey]; ok {
+		keys = append(keys, string(previous))
+	}
+
+	return keys, nil
+}
+
 // IsCreating ...
 func (r *NatsAccountReconciler) IsCreating(obj *natsv1alpha1.NatsAccount) bool {
 	return utilx.Or(obj.Status.Conditions == nil, slices.Size(0, obj.Status.Conditions))
@@ -236,7 +502,6 @@ func (r *NatsAccountReconciler) ManageError(ctx context.Context, obj *natsv1alph
 // ManageSuccess ...
 func (r *NatsAccountReconciler) ManageSuccess(ctx context.Context, obj *natsv1alpha1.NatsAccount) (ctrl.Result, error) {
 	obj.Status.Phase = natsv1alpha1.AccountPhaseSynchronized
-	obj.Status.LastUpdate = metav1.Now()
 	status.SetNatzAccountCondition(obj, status.NewAccountSychronizedCondition(obj))
 
 	err := r.Status().Update(ctx, obj)
@@ -246,13 +511,133 @@ func (r *NatsAccountReconciler) ManageSuccess(ctx context.Context, obj *natsv1al
 
 	r.Recorder.Event(obj, corev1.EventTypeNormal, conv.String(EventReasonAccountSychronized), "account synchronized")
 
+	if next := earliest(obj.NextRevocationExpiry(), obj.NextImportExpiry()); !next.IsZero() {
+		// an expiry that just passed still has to be pruned
+		return ctrl.Result{RequeueAfter: max(time.Until(next), time.Second)}, nil
+	}
+
 	return ctrl.Result{}, nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *NatsAccountReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	err := mgr.GetFieldIndexer().IndexField(context.Background(), &natsv1alpha1.NatsAccount{}, keyRefIndex, func(obj client.Object) []string {
+		account, ok := obj.(*natsv1alpha1.NatsAccount)
+		if !ok {
+			return nil
+		}
+
+		refs := []string{
+			indexRef(utilx.Or(account.Spec.SignerKeyRef.Namespace, account.Namespace), account.Spec.SignerKeyRef.Name),
+			indexRef(account.Namespace, account.Spec.PrivateKey.Name),
+		}
+		for _, key := range account.Spec.SigningKeys {
+			refs = append(refs, indexRef(account.Namespace, key.Name))
+		}
+		for _, key := range account.Spec.ScopedSigningKeys {
+			refs = append(refs, indexRef(account.Namespace, key.Name))
+		}
+		for _, key := range account.Spec.Authorization.AuthUserKeyRefs {
+			refs = append(refs, indexRef(account.Namespace, key.Name))
+		}
+		if ref := account.Spec.Authorization.XKeyRef; ref != nil {
+			refs = append(refs, indexRef(account.Namespace, ref.Name))
+		}
+
+		return refs
+	})
+	if err != nil {
+		return err
+	}
+
+	err = mgr.GetFieldIndexer().IndexField(context.Background(), &natsv1alpha1.NatsAccount{}, accountRefIndex, func(obj client.Object) []string {
+		account, ok := obj.(*natsv1alpha1.NatsAccount)
+		if !ok {
+			return nil
+		}
+
+		refs := []string{}
+		for _, imp := range account.Spec.AccountImports {
+			refs = append(refs, indexRef(utilx.Or(imp.AccountRef.Namespace, account.Namespace), imp.AccountRef.Name))
+		}
+
+		return refs
+	})
+	if err != nil {
+		return err
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&natsv1alpha1.NatsAccount{}).
 		Owns(&corev1.Secret{}).
+		Owns(&corev1.ConfigMap{}).
+		// the revocations ConfigMap is not owned by the account
+		Watches(&corev1.ConfigMap{}, handler.EnqueueRequestsFromMapFunc(enqueueForRevocations)).
+		Watches(&natsv1alpha1.NatsKey{}, handler.EnqueueRequestsFromMapFunc(enqueueReferencing(r.Client, &natsv1alpha1.NatsAccountList{}, keyRefIndex))).
+		Watches(&natsv1alpha1.NatsOperator{}, handler.EnqueueRequestsFromMapFunc(r.enqueueForOperator), builder.WithPredicates(operatorChanged())).
+		Watches(&natsv1alpha1.NatsAccount{}, handler.EnqueueRequestsFromMapFunc(enqueueReferencing(r.Client, &natsv1alpha1.NatsAccountList{}, accountRefIndex)), builder.WithPredicates(accountChanged())).
+		Watches(&natsv1alpha1.NatsActivation{}, handler.EnqueueRequestsFromMapFunc(r.enqueueForActivation), builder.WithPredicates(activationChanged())).
 		Complete(r)
 }
+
+// enqueueForOperator enqueues all accounts signed by one of the keys of the operator.
+func (r *NatsAccountReconciler) enqueueForOperator(ctx context.Context, obj client.Object) []reconcile.Request {
+	operator, ok := obj.(*natsv1alpha1.NatsOperator)
+	if !ok {
+		return nil
+	}
+
+	enqueue := enqueueReferencing(r.Client, &natsv1alpha1.NatsAccountList{}, keyRefIndex)
+
+	keys := []string{operator.Spec.PrivateKey.Name}
+	for _, key := range operator.Spec.SigningKeys {
+		keys = append(keys, key.Name)
+	}
+
+	requests := []reconcile.Request{}
+	for _, key := range keys {
+		k := &natsv1alpha1.NatsKey{}
+		k.Namespace = operator.Namespace
+		k.Name = key
+
+		requests = append(requests, enqueue(ctx, k)...)
+	}
+
+	return requests
+}
+
+// enqueueForActivation enqueues the issuing and the target account of the activation.
+func (r *NatsAccountReconciler) enqueueForActivation(_ context.Context, obj client.Object) []reconcile.Request {
+	activation, ok := obj.(*natsv1alpha1.NatsActivation)
+	if !ok {
+		return nil
+	}
+
+	return []reconcile.Request{
+		{
+			NamespacedName: client.ObjectKey{
+				Namespace: activation.Namespace,
+				Name:      activation.Spec.AccountRef.Name,
+			},
+		},
+		{
+			NamespacedName: client.ObjectKey{
+				Namespace: utilx.Or(activation.Spec.TargetAccountRef.Namespace, activation.Namespace),
+				Name:      activation.Spec.TargetAccountRef.Name,
+			},
+		},
+	}
+}
+
+// earliest returns the earliest of the non-zero times.
+func earliest(times ...time.Time) time.Time {
+	next := time.Time{}
+
+	for _, t := range times {
+		if !t.IsZero() && (next.IsZero() || t.Before(next)) {
+			next = t
+		}
+	}
+
+	return next
+}
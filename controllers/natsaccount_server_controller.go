@@ -1,9 +1,12 @@
 package controllers
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"fmt"
 	"math"
-	"sync"
+	"strings"
 	"time"
 
 	natsv1alpha1 "github.com/katallaxie/natz-operator/api/v1alpha1"
@@ -22,14 +25,29 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
+const (
+	// AccountLookupSubject is the subject a nats server requests an account JWT on.
+	AccountLookupSubject = "$SYS.REQ.ACCOUNT.*.CLAIMS.LOOKUP"
+	// AccountPackSubject is the subject a nats server requests all account JWTs on.
+	AccountPackSubject = "$SYS.REQ.CLAIMS.PACK"
+	// AccountUpdateSubject is the subject to push an updated account JWT to.
+	AccountUpdateSubject = "$SYS.REQ.CLAIMS.UPDATE"
+	// AccountDeleteSubject is the subject to push deleted accounts to.
+	AccountDeleteSubject = "$SYS.REQ.CLAIMS.DELETE"
+	// AccountServerQueue is the queue group of the account servers.
+	AccountServerQueue = "natz-account-server"
+)
+
 // NatsAccountServer takes NatsAccount and serves them to a nats server (cluster).
+// The accounts are served from the shared informer cache by every replica,
+// only the leader pushes the updated and deleted accounts.
 type NatsAccountServer struct {
 	client.Client
 	Scheme   *runtime.Scheme
-	accounts sync.Map
 	nc       *nats.Conn
 	Recorder record.EventRecorder
 }
@@ -47,21 +65,132 @@ func NewNatsAccountServer(mgr ctrl.Manager, nc *nats.Conn) *NatsAccountServer {
 	}
 }
 
-// GetJWT ...
-func (r *NatsAccountServer) GetJWT(publicKey string) (string, bool) {
-	jwt, ok := r.accounts.Load(publicKey)
+// GetJWT returns the JWT of the synchronized account with the public key.
+func (r *NatsAccountServer) GetJWT(ctx context.Context, publicKey string) (string, bool, error) {
+	accounts, err := r.served(ctx, client.MatchingFields{accountPublicKeyIndex: publicKey})
+	if err != nil || len(accounts) == 0 {
+		return "", false, err
+	}
+
+	return accounts[0].Status.JWT, true, nil
+}
+
+// Hash returns the hash of the account JWTs.
+// The hash is compatible with the hash of the nats server directory resolver.
+func Hash(accounts []natsv1alpha1.NatsAccount) [sha256.Size]byte {
+	var hash [sha256.Size]byte
+
+	for _, account := range accounts {
+		h := sha256.Sum256([]byte(account.Status.JWT))
+		for i := range hash {
+			hash[i] ^= h[i]
+		}
+	}
+
+	return hash
+}
+
+// served returns the synchronized accounts that are not deleted.
+func (r *NatsAccountServer) served(ctx context.Context, opts ...client.ListOption) ([]natsv1alpha1.NatsAccount, error) {
+	list := &natsv1alpha1.NatsAccountList{}
+	if err := r.List(ctx, list, opts...); err != nil {
+		return nil, err
+	}
+
+	accounts := []natsv1alpha1.NatsAccount{}
+	for _, account := range list.Items {
+		if r.IsSynchronized(&account) && account.Status.JWT != "" && account.DeletionTimestamp.IsZero() {
+			accounts = append(accounts, account)
+		}
+	}
+
+	return accounts, nil
+}
+
+// NeedLeaderElection returns false, all replicas respond to the lookup and pack requests.
+func (r *NatsAccountServer) NeedLeaderElection() bool {
+	return false
+}
+
+// Start subscribes to the account lookup and pack requests of the nats servers.
+func (r *NatsAccountServer) Start(ctx context.Context) error {
+	lookup, err := r.nc.QueueSubscribe(AccountLookupSubject, AccountServerQueue, func(msg *nats.Msg) {
+		r.handleLookup(ctx, msg)
+	})
+	if err != nil {
+		return err
+	}
+	defer lookup.Unsubscribe() //nolint:errcheck
+
+	pack, err := r.nc.QueueSubscribe(AccountPackSubject, AccountServerQueue, func(msg *nats.Msg) {
+		r.handlePack(ctx, msg)
+	})
+	if err != nil {
+		return err
+	}
+	defer pack.Unsubscribe() //nolint:errcheck
+
+	<-ctx.Done()
+
+	return nil
+}
+
+// handleLookup responds with the JWT of the requested account.
+// Unknown accounts are not answered, so other resolvers can respond.
+func (r *NatsAccountServer) handleLookup(ctx context.Context, msg *nats.Msg) {
+	// $SYS.REQ.ACCOUNT.<public key>.CLAIMS.LOOKUP
+	tokens := strings.Split(msg.Subject, ".")
+	if len(tokens) != 6 {
+		return
+	}
+
+	t, ok, err := r.GetJWT(ctx, tokens[3])
+	if err != nil {
+		log.FromContext(ctx).Error(err, "looking up account", "account", tokens[3])
+		return
+	}
+
 	if !ok {
-		return "", false
+		return
 	}
 
-	return conv.String(jwt), true
+	_ = msg.Respond([]byte(t))
+}
+
+// handlePack responds with all account JWTs as pack messages of the form <public key>|<jwt>.
+// An empty message signals the end of the response.
+func (r *NatsAccountServer) handlePack(ctx context.Context, msg *nats.Msg) {
+	if msg.Reply == "" {
+		return
+	}
+
+	accounts, err := r.served(ctx)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "listing accounts")
+		return
+	}
+
+	hash := Hash(accounts)
+	if !bytes.Equal(msg.Data, hash[:]) {
+		for _, account := range accounts {
+			if err := msg.Respond([]byte(fmt.Sprintf("%s|%s", account.Status.PublicKey, account.Status.JWT))); err != nil {
+				break
+			}
+		}
+	}
+
+	_ = msg.Respond([]byte{})
 }
 
 // Reconcile ...
 func (r *NatsAccountServer) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	account := &natsv1alpha1.NatsAccount{}
 
-	if err := r.Get(ctx, req.NamespacedName, account); err != nil && !errors.IsNotFound(err) {
+	if err := r.Get(ctx, req.NamespacedName, account); err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+
 		return ctrl.Result{}, err
 	}
 
@@ -122,7 +251,7 @@ func (r *NatsAccountServer) reconcileDelete(ctx context.Context, obj *natsv1alph
 			return ctrl.Result{}, err
 		}
 
-		err = r.nc.Publish("$SYS.REQ.CLAIMS.DELETE", []byte(t))
+		err = r.nc.Publish(AccountDeleteSubject, []byte(t))
 		if err != nil {
 			return ctrl.Result{}, err
 		}
@@ -138,8 +267,9 @@ func (r *NatsAccountServer) reconcileDelete(ctx context.Context, obj *natsv1alph
 	return ctrl.Result{}, nil
 }
 
+// reconcileAccount pushes the JWT of the account to the nats servers.
 func (r *NatsAccountServer) reconcileAccount(_ context.Context, obj *natsv1alpha1.NatsAccount) error {
-	return r.nc.Publish("$SYS.REQ.CLAIMS.UPDATE", []byte(obj.Status.JWT))
+	return r.nc.Publish(AccountUpdateSubject, []byte(obj.Status.JWT))
 }
 
 // IsCreating ...
@@ -179,6 +309,22 @@ func (r *NatsAccountServer) ManageError(ctx context.Context, obj *natsv1alpha1.N
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *NatsAccountServer) SetupWithManager(mgr ctrl.Manager) error {
+	err := mgr.GetFieldIndexer().IndexField(context.Background(), &natsv1alpha1.NatsAccount{}, accountPublicKeyIndex, func(obj client.Object) []string {
+		account, ok := obj.(*natsv1alpha1.NatsAccount)
+		if !ok || account.Status.PublicKey == "" {
+			return nil
+		}
+
+		return []string{account.Status.PublicKey}
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := mgr.Add(r); err != nil {
+		return err
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&natsv1alpha1.NatsAccount{}).
 		Complete(r)
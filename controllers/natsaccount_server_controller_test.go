@@ -0,0 +1,45 @@
+package controllers_test
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	natsv1alpha1 "github.com/katallaxie/natz-operator/api/v1alpha1"
+	"github.com/katallaxie/natz-operator/controllers"
+	"github.com/stretchr/testify/require"
+)
+
+func account(jwt string) natsv1alpha1.NatsAccount {
+	return natsv1alpha1.NatsAccount{Status: natsv1alpha1.NatsAccountStatus{JWT: jwt}}
+}
+
+func TestHash(t *testing.T) {
+	t.Parallel()
+
+	a := sha256.Sum256([]byte("a.jwt"))
+	b := sha256.Sum256([]byte("b.jwt"))
+
+	var ab [sha256.Size]byte
+	for i := range ab {
+		ab[i] = a[i] ^ b[i]
+	}
+
+	tests := []struct {
+		name     string
+		accounts []natsv1alpha1.NatsAccount
+		expected [sha256.Size]byte
+	}{
+		{name: "no accounts", accounts: nil, expected: [sha256.Size]byte{}},
+		{name: "one account", accounts: []natsv1alpha1.NatsAccount{account("a.jwt")}, expected: a},
+		{name: "accounts", accounts: []natsv1alpha1.NatsAccount{account("a.jwt"), account("b.jwt")}, expected: ab},
+		{name: "accounts in any order", accounts: []natsv1alpha1.NatsAccount{account("b.jwt"), account("a.jwt")}, expected: ab},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tc.expected, controllers.Hash(tc.accounts))
+		})
+	}
+}
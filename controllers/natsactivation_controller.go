@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"context"
+	"fmt"
 	"math"
 	"time"
 
@@ -11,12 +12,16 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	natsv1alpha1 "github.com/katallaxie/natz-operator/api/v1alpha1"
 	"github.com/katallaxie/natz-operator/pkg/status"
+	"github.com/katallaxie/natz-operator/pkg/utils"
 	"github.com/katallaxie/pkg/cast"
 	"github.com/katallaxie/pkg/conv"
 	"github.com/katallaxie/pkg/k8s/finalizers"
@@ -24,11 +29,15 @@ import (
 	"github.com/katallaxie/pkg/utilx"
 	"github.com/nats-io/jwt/v2"
 	"github.com/nats-io/nkeys"
+	"github.com/samber/lo"
 )
 
 const (
 	EventReasonActivationSynchronized EventReason = "ActivationSyncronized"
 	EventReasonActivationFailed       EventReason = "ActivationFailed"
+	EventReasonActivationRevoked      EventReason = "ActivationRevoked"
+	EventReasonActivationRenewed      EventReason = "ActivationRenewed"
+	EventReasonActivationExpiring     EventReason = "ActivationExpiring"
 )
 
 // NatsActivationReconciler ...
@@ -83,6 +92,12 @@ func (r *NatsActivationReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 }
 
 func (r *NatsActivationReconciler) reconcileDelete(ctx context.Context, obj *natsv1alpha1.NatsActivation) (ctrl.Result, error) {
+	if controllerutil.ContainsFinalizer(obj, natsv1alpha1.FinalizerName) && obj.Status.PublicKey != "" {
+		if err := r.reconcileRevocation(ctx, obj, obj.Status.PublicKey); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
 	obj.SetFinalizers(finalizers.RemoveFinalizer(obj, natsv1alpha1.FinalizerName))
 
 	err := r.Update(ctx, obj)
@@ -93,7 +108,51 @@ func (r *NatsActivationReconciler) reconcileDelete(ctx context.Context, obj *nat
 	return ctrl.Result{}, nil
 }
 
+// reconcileRevocation revokes the activation for the public key in the exports of the issuing account.
+func (r *NatsActivationReconciler) reconcileRevocation(ctx context.Context, obj *natsv1alpha1.NatsActivation, publicKey string) error {
+	account := &natsv1alpha1.NatsAccount{}
+	accountName := client.ObjectKey{
+		Namespace: obj.Namespace,
+		Name:      obj.Spec.AccountRef.Name,
+	}
+
+	if err := r.Get(ctx, accountName, account); err != nil {
+		// the activation is gone with the account
+		return client.IgnoreNotFound(err)
+	}
+
+	revocation := natsv1alpha1.ActivationRevocation{
+		Name:      client.ObjectKeyFromObject(obj).String(),
+		Subject:   obj.Spec.Subject,
+		PublicKey: publicKey,
+		RevokedAt: metav1.Now(),
+		Expiry:    obj.Status.Expiry,
+	}
+
+	// the revocations are written into the status of the account by the account controller
+	if err := addRevocations(ctx, r.Client, account, nil, []natsv1alpha1.ActivationRevocation{revocation}); err != nil {
+		return err
+	}
+
+	r.Recorder.Eventf(account, corev1.EventTypeNormal, conv.String(EventReasonActivationRevoked), "activation %s revoked for %s", client.ObjectKeyFromObject(obj), publicKey)
+
+	return nil
+}
+
+func (r *NatsActivationReconciler) reconcileFinalizer(ctx context.Context, obj *natsv1alpha1.NatsActivation) error {
+	if !controllerutil.ContainsFinalizer(obj, natsv1alpha1.FinalizerName) {
+		controllerutil.AddFinalizer(obj, natsv1alpha1.FinalizerName)
+		return r.Update(ctx, obj)
+	}
+
+	return nil
+}
+
 func (r *NatsActivationReconciler) reconcileResources(ctx context.Context, obj *natsv1alpha1.NatsActivation) error {
+	if err := r.reconcileFinalizer(ctx, obj); err != nil {
+		return err
+	}
+
 	if err := r.reconcileStatus(ctx, obj); err != nil {
 		return err
 	}
@@ -102,7 +161,10 @@ func (r *NatsActivationReconciler) reconcileResources(ctx context.Context, obj *
 		return err
 	}
 
-	return nil
+	published, err := publish(ctx, r.Client, r.Scheme, obj, obj.Spec.Publish, obj.Status.Published, "activation", obj.Status.JWT, obj.Status.PublicKey)
+	obj.Status.Published = published
+
+	return err
 }
 
 func (r *NatsActivationReconciler) reconcileActivation(ctx context.Context, obj *natsv1alpha1.NatsActivation) error {
@@ -116,16 +178,20 @@ func (r *NatsActivationReconciler) reconcileActivation(ctx context.Context, obj
 		return err
 	}
 
-	skAccount := &natsv1alpha1.NatsAccount{}
-	skAccountName := client.ObjectKey{
-		Namespace: obj.Namespace,
-		Name:      obj.Spec.AccountRef.Name,
+	target := &natsv1alpha1.NatsAccount{}
+	targetName := client.ObjectKey{
+		Namespace: utilx.Or(obj.Spec.TargetAccountRef.Namespace, obj.Namespace),
+		Name:      obj.Spec.TargetAccountRef.Name,
 	}
 
-	if err := r.Get(ctx, skAccountName, skAccount); err != nil {
+	if err := r.Get(ctx, targetName, target); err != nil {
 		return err
 	}
 
+	if target.Status.PublicKey == "" {
+		return fmt.Errorf("account %s is not synchronized", targetName)
+	}
+
 	sk := &natsv1alpha1.NatsKey{}
 	skName := client.ObjectKey{
 		Namespace: obj.Namespace,
@@ -151,12 +217,32 @@ func (r *NatsActivationReconciler) reconcileActivation(ctx context.Context, obj
 		return err
 	}
 
-	token := jwt.NewActivationClaims(account.Status.PublicKey)
+	// the activation is issued by the exporting account for the importing account
+	token := jwt.NewActivationClaims(target.Status.PublicKey)
 	token.Name = obj.Spec.Subject
-	token.IssuerAccount = skAccount.Status.PublicKey
 
-	token.NotBefore = obj.Spec.Start.Unix()
-	token.Expires = obj.Spec.Expiry.Unix()
+	signer, err := signerKp.PublicKey()
+	if err != nil {
+		return err
+	}
+
+	// activations signed by a signing key carry the exporting account
+	if signer != account.Status.PublicKey {
+		token.IssuerAccount = account.Status.PublicKey
+	}
+
+	expiry, err := r.activationExpiry(obj)
+	if err != nil {
+		return err
+	}
+
+	if !obj.Spec.Start.IsZero() {
+		token.NotBefore = obj.Spec.Start.Unix()
+	}
+
+	if !expiry.IsZero() {
+		token.Expires = expiry.Unix()
+	}
 
 	token.Activation.ImportSubject = jwt.Subject(obj.Spec.Subject)
 	token.Activation.ImportType = jwt.ExportType(obj.Spec.ExportType)
@@ -166,11 +252,83 @@ func (r *NatsActivationReconciler) reconcileActivation(ctx context.Context, obj
 		return err
 	}
 
-	obj.Status.JWT = t
+	// the previously activated account is revoked when the target changed
+	if obj.Status.PublicKey != "" && obj.Status.PublicKey != target.Status.PublicKey {
+		if err := r.reconcileRevocation(ctx, obj, obj.Status.PublicKey); err != nil {
+			return err
+		}
+
+		obj.Status.RevokedPublicKeys = append(lo.Without(obj.Status.RevokedPublicKeys, obj.Status.PublicKey), obj.Status.PublicKey)
+	}
+
+	t = reuseToken(obj.Status.JWT, t)
+	if t != obj.Status.JWT || obj.Status.ObservedGeneration != obj.Generation {
+		if !expiry.Equal(obj.Status.Expiry.Time) || obj.Status.ObservedGeneration != obj.Generation {
+			obj.Status.RenewAt = metav1.Time{}
+			obj.Status.ExpiringReportedAt = metav1.Time{}
+
+			if !expiry.IsZero() {
+				obj.Status.RenewAt = metav1.NewTime(obj.Spec.RenewAt(time.Unix(token.IssuedAt, 0), expiry))
+			}
+
+			if obj.Status.JWT != "" && obj.Status.ObservedGeneration == obj.Generation {
+				r.Recorder.Eventf(obj, corev1.EventTypeNormal, conv.String(EventReasonActivationRenewed), "activation renewed until %s", expiry.Format(time.RFC3339))
+			}
+		}
+
+		obj.Status.JWT = t
+		obj.Status.Expiry = metav1.NewTime(expiry)
+		obj.Status.ObservedGeneration = obj.Generation
+		obj.Status.LastUpdate = metav1.Now()
+	}
+
+	obj.Status.PublicKey = target.Status.PublicKey
+
+	// activations with a fixed expiry are not renewed, they are reported once before they lapse
+	if obj.Spec.TTL == "" && !obj.Status.RenewAt.IsZero() && !time.Now().Before(obj.Status.RenewAt.Time) && obj.Status.ExpiringReportedAt.IsZero() {
+		r.Recorder.Eventf(obj, corev1.EventTypeWarning, conv.String(EventReasonActivationExpiring), "activation expires at %s", expiry.Format(time.RFC3339))
+		obj.Status.ExpiringReportedAt = metav1.Now()
+	}
 
 	return nil
 }
 
+// activationExpiry returns the expiry of the activation JWT.
+// The expiry of an activation with a TTL is kept until it is due for renewal or the spec changed.
+func (r *NatsActivationReconciler) activationExpiry(obj *natsv1alpha1.NatsActivation) (time.Time, error) {
+	if obj.Spec.TTL == "" {
+		return obj.Spec.Expiry.Time, nil
+	}
+
+	if obj.Status.ObservedGeneration == obj.Generation && !obj.Status.Expiry.IsZero() && time.Now().Before(obj.Status.RenewAt.Time) {
+		return obj.Status.Expiry.Time, nil
+	}
+
+	expiry, err := utils.ParseExpiry(obj.Spec.TTL)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if expiry == 0 {
+		return time.Time{}, nil
+	}
+
+	if expiry <= time.Now().Unix() {
+		return time.Time{}, fmt.Errorf("ttl %q of activation %s is in the past", obj.Spec.TTL, obj.Name)
+	}
+
+	return time.Unix(expiry, 0), nil
+}
+
+// renewAfter returns the duration until the activation is renewed or about to expire.
+func (r *NatsActivationReconciler) renewAfter(obj *natsv1alpha1.NatsActivation) time.Duration {
+	if obj.Status.RenewAt.IsZero() {
+		return 0
+	}
+
+	return max(time.Until(obj.Status.RenewAt.Time), 0)
+}
+
 func (r *NatsActivationReconciler) reconcileStatus(_ context.Context, _ *natsv1alpha1.NatsActivation) error {
 	return nil
 }
@@ -214,7 +372,6 @@ func (r *NatsActivationReconciler) ManageError(ctx context.Context, obj *natsv1a
 // ManageSuccess ...
 func (r *NatsActivationReconciler) ManageSuccess(ctx context.Context, obj *natsv1alpha1.NatsActivation) (ctrl.Result, error) {
 	obj.Status.Phase = natsv1alpha1.ActivationSynchronized
-	obj.Status.LastUpdate = metav1.Now()
 	status.SetNatzActivationCondition(obj, status.NewNatzActivationSynchronizedCondition(obj))
 
 	err := r.Status().Update(ctx, obj)
@@ -224,13 +381,43 @@ func (r *NatsActivationReconciler) ManageSuccess(ctx context.Context, obj *natsv
 
 	r.Recorder.Event(obj, corev1.EventTypeNormal, conv.String(EventReasonAccountSychronized), "account synchronized")
 
-	return ctrl.Result{}, nil
+	return ctrl.Result{RequeueAfter: r.renewAfter(obj)}, nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *NatsActivationReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	err := mgr.GetFieldIndexer().IndexField(context.Background(), &natsv1alpha1.NatsActivation{}, keyRefIndex, func(obj client.Object) []string {
+		activation, ok := obj.(*natsv1alpha1.NatsActivation)
+		if !ok {
+			return nil
+		}
+
+		return []string{indexRef(activation.Namespace, activation.Spec.SignerKeyRef.Name)}
+	})
+	if err != nil {
+		return err
+	}
+
+	err = mgr.GetFieldIndexer().IndexField(context.Background(), &natsv1alpha1.NatsActivation{}, accountRefIndex, func(obj client.Object) []string {
+		activation, ok := obj.(*natsv1alpha1.NatsActivation)
+		if !ok {
+			return nil
+		}
+
+		return []string{
+			indexRef(activation.Namespace, activation.Spec.AccountRef.Name),
+			indexRef(utilx.Or(activation.Spec.TargetAccountRef.Namespace, activation.Namespace), activation.Spec.TargetAccountRef.Name),
+		}
+	})
+	if err != nil {
+		return err
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&natsv1alpha1.NatsActivation{}).
 		Owns(&corev1.Secret{}).
+		Owns(&corev1.ConfigMap{}).
+		Watches(&natsv1alpha1.NatsKey{}, handler.EnqueueRequestsFromMapFunc(enqueueReferencing(r.Client, &natsv1alpha1.NatsActivationList{}, keyRefIndex))).
+		Watches(&natsv1alpha1.NatsAccount{}, handler.EnqueueRequestsFromMapFunc(enqueueReferencing(r.Client, &natsv1alpha1.NatsActivationList{}, accountRefIndex)), builder.WithPredicates(accountChanged())).
 		Complete(r)
 }
@@ -0,0 +1,395 @@
+package controllers
+
+import (
+	"context"
+	goerrors "errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	natsv1alpha1 "github.com/katallaxie/natz-operator/api/v1alpha1"
+	"github.com/katallaxie/natz-operator/pkg/utils"
+
+	"github.com/katallaxie/pkg/utilx"
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nkeys"
+	"github.com/samber/lo"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// AuthCalloutSubject is the subject a nats server sends authorization requests to.
+	AuthCalloutSubject = "$SYS.REQ.USER.AUTH"
+	// AuthCalloutQueue is the queue group of the auth callout responders.
+	AuthCalloutQueue = "natz-auth-callout"
+	// AuthCalloutXKeyHeader is the header with the curve public key of the requesting nats server.
+	AuthCalloutXKeyHeader = "Nats-Server-Xkey"
+	// DefaultAuthCalloutTTL is the default validity of the user JWTs issued by the auth callout.
+	DefaultAuthCalloutTTL = time.Hour
+	// DefaultAuthCalloutAudience is the default audience of the ServiceAccount tokens of the clients.
+	DefaultAuthCalloutAudience = "nats"
+)
+
+const (
+	// serviceAccountUsernamePrefix is the prefix of the usernames of ServiceAccounts.
+	serviceAccountUsernamePrefix = "system:serviceaccount:"
+	// podNameExtraKey is the extra of a reviewed token with the name of the pod the token is bound to.
+	podNameExtraKey = "authentication.kubernetes.io/pod-name"
+)
+
+var (
+	// ErrUnauthorized is returned when a client can not be authorized.
+	ErrUnauthorized = goerrors.New("unauthorized")
+	// ErrNoAudiences is returned when the auth callout has no token audiences.
+	// Tokens with the audience of the API server must not be accepted, as they are API credentials.
+	ErrNoAudiences = goerrors.New("the auth callout requires at least one token audience")
+)
+
+// NatsAuthCallout authorizes nats clients with the tokens of Kubernetes ServiceAccounts.
+// The token is verified with a TokenReview and the client is issued a user JWT
+// with the permissions of the NatsUser that is bound to the ServiceAccount.
+type NatsAuthCallout struct {
+	client.Client
+	// secrets reads the seeds without caching all secrets of the cluster
+	secrets   client.Reader
+	nc        *nats.Conn
+	issuer    client.ObjectKey
+	xkey      client.ObjectKey
+	audiences []string
+	ttl       time.Duration
+}
+
+//+kubebuilder:rbac:groups=authentication.k8s.io,resources=tokenreviews,verbs=create
+//+kubebuilder:rbac:groups=natz.katallaxie.dev,resources=natsusers;natskeys,verbs=get;list;watch
+//+kubebuilder:rbac:groups=,resources=secrets,verbs=get
+
+// NewNatsAuthCallout returns an auth callout that signs the responses with the issuer key,
+// and encrypts them with the curve key if it is named.
+func NewNatsAuthCallout(mgr ctrl.Manager, nc *nats.Conn, issuer, xkey client.ObjectKey, ttl time.Duration, audiences ...string) *NatsAuthCallout {
+	return &NatsAuthCallout{
+		Client:    mgr.GetClient(),
+		secrets:   mgr.GetAPIReader(),
+		nc:        nc,
+		issuer:    issuer,
+		xkey:      xkey,
+		audiences: lo.Compact(audiences),
+		ttl:       utilx.Or(ttl, DefaultAuthCalloutTTL),
+	}
+}
+
+// NeedLeaderElection returns false, all replicas respond to the authorization requests.
+func (r *NatsAuthCallout) NeedLeaderElection() bool {
+	return false
+}
+
+// Start subscribes to the authorization requests of the nats servers.
+func (r *NatsAuthCallout) Start(ctx context.Context) error {
+	sub, err := r.nc.QueueSubscribe(AuthCalloutSubject, AuthCalloutQueue, func(msg *nats.Msg) {
+		r.handleAuth(ctx, msg)
+	})
+	if err != nil {
+		return err
+	}
+	defer sub.Unsubscribe() //nolint:errcheck
+
+	<-ctx.Done()
+
+	return nil
+}
+
+// handleAuth responds to an authorization request.
+// Requests that can not be decoded are not answered, the nats server rejects the client after a timeout.
+func (r *NatsAuthCallout) handleAuth(ctx context.Context, msg *nats.Msg) {
+	logger := log.FromContext(ctx).WithValues("subject", msg.Subject)
+
+	serverXKey := msg.Header.Get(AuthCalloutXKeyHeader)
+
+	data, err := r.open(ctx, msg.Data, serverXKey)
+	if err != nil {
+		logger.Error(err, "decrypting authorization request")
+		return
+	}
+
+	req, err := jwt.DecodeAuthorizationRequestClaims(string(data))
+	if err != nil {
+		logger.Error(err, "decoding authorization request")
+		return
+	}
+
+	resp := jwt.NewAuthorizationResponseClaims(req.UserNkey)
+	resp.Audience = req.Server.ID
+
+	resp.Jwt, err = r.authorize(ctx, req)
+	if err != nil {
+		// the reason is only logged, the client is not told about the resources of the cluster
+		logger.Info("authorization failed", "client", req.ClientInformation.Host, "error", err.Error())
+		resp.Error = ErrUnauthorized.Error()
+	}
+
+	issuer, issuerAccount, err := r.issuerKey(ctx)
+	if err != nil {
+		logger.Error(err, "loading auth callout issuer")
+		return
+	}
+	resp.IssuerAccount = issuerAccount
+
+	token, err := resp.Encode(issuer)
+	if err != nil {
+		logger.Error(err, "encoding authorization response")
+		return
+	}
+
+	out, err := r.seal(ctx, []byte(token), serverXKey)
+	if err != nil {
+		logger.Error(err, "encrypting authorization response")
+		return
+	}
+
+	_ = msg.Respond(out)
+}
+
+// authorize reviews the token of the client and returns the user JWT of the NatsUser bound to its ServiceAccount.
+//
+//nolint:gocyclo
+func (r *NatsAuthCallout) authorize(ctx context.Context, req *jwt.AuthorizationRequestClaims) (string, error) {
+	token := utilx.Or(req.ConnectOptions.Token, req.ConnectOptions.Password)
+	if token == "" {
+		return "", fmt.Errorf("%w: no token", ErrUnauthorized)
+	}
+
+	review := &authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{
+			Token:     token,
+			Audiences: r.audiences,
+		},
+	}
+
+	if err := r.Create(ctx, review); err != nil {
+		return "", err
+	}
+
+	if !review.Status.Authenticated {
+		return "", fmt.Errorf("%w: %s", ErrUnauthorized, utilx.Or(review.Status.Error, "token is not authenticated"))
+	}
+
+	namespace, serviceAccount, ok := splitServiceAccount(review.Status.User.Username)
+	if !ok {
+		return "", fmt.Errorf("%w: %s is not a service account", ErrUnauthorized, review.Status.User.Username)
+	}
+
+	user, err := r.serviceAccountUser(ctx, namespace, serviceAccount)
+	if err != nil {
+		return "", err
+	}
+
+	account := &natsv1alpha1.NatsAccount{}
+	if err := r.Get(ctx, accountName(user), account); err != nil {
+		return "", err
+	}
+
+	signerSecret := &corev1.Secret{}
+	if err := r.secrets.Get(ctx, signerKeyName(user, account), signerSecret); err != nil {
+		return "", err
+	}
+
+	signer, err := nkeys.FromSeed(signerSecret.Data[natsv1alpha1.SecretSeedDataKey])
+	if err != nil {
+		return "", err
+	}
+
+	trust, err := userTrust(user, account, signer)
+	if err != nil {
+		return "", err
+	}
+
+	name := serviceAccount
+	tags := []string{
+		fmt.Sprintf("%s:%s", natsv1alpha1.TagNamespace, namespace),
+		fmt.Sprintf("%s:%s", natsv1alpha1.TagServiceAccount, serviceAccount),
+	}
+
+	if pods := review.Status.User.Extra[podNameExtraKey]; len(pods) > 0 {
+		name = pods[0]
+		tags = append(tags, fmt.Sprintf("%s:%s", natsv1alpha1.TagPod, pods[0]))
+	}
+
+	expiry, err := r.expiry(user)
+	if err != nil {
+		return "", err
+	}
+
+	claims, err := userClaims(user, name, req.UserNkey, trust, expiry, tags...)
+	if err != nil {
+		return "", err
+	}
+
+	return claims.Encode(signer)
+}
+
+// serviceAccountUser returns the synchronized NatsUser that is bound to the ServiceAccount.
+func (r *NatsAuthCallout) serviceAccountUser(ctx context.Context, namespace, serviceAccount string) (*natsv1alpha1.NatsUser, error) {
+	list := &natsv1alpha1.NatsUserList{}
+	if err := r.List(ctx, list, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+
+	users := []natsv1alpha1.NatsUser{}
+	for _, user := range list.Items {
+		if user.Spec.ServiceAccountName == serviceAccount && user.DeletionTimestamp.IsZero() {
+			users = append(users, user)
+		}
+	}
+
+	switch len(users) {
+	case 0:
+		return nil, fmt.Errorf("%w: no user is bound to service account %s/%s", ErrUnauthorized, namespace, serviceAccount)
+	case 1:
+	default:
+		sort.Slice(users, func(i, j int) bool { return users[i].Name < users[j].Name })
+		return nil, fmt.Errorf("%w: users %s and %s are bound to service account %s/%s", ErrUnauthorized, users[0].Name, users[1].Name, namespace, serviceAccount)
+	}
+
+	user := &users[0]
+	if user.Status.Phase != natsv1alpha1.UserPhaseSynchronized {
+		return nil, fmt.Errorf("%w: user %s is not synchronized", ErrUnauthorized, client.ObjectKeyFromObject(user))
+	}
+
+	return user, nil
+}
+
+// expiry returns the expiry of an issued JWT, the TTL is bounded by the expiry of the user.
+func (r *NatsAuthCallout) expiry(user *natsv1alpha1.NatsUser) (time.Time, error) {
+	expiry := time.Now().Add(r.ttl)
+
+	e, err := utils.ParseExpiry(user.Spec.Expiry)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if e == 0 || expiry.Before(time.Unix(e, 0)) {
+		return expiry, nil
+	}
+
+	if !time.Now().Before(time.Unix(e, 0)) {
+		return time.Time{}, fmt.Errorf("%w: user %s is expired", ErrUnauthorized, client.ObjectKeyFromObject(user))
+	}
+
+	return time.Unix(e, 0), nil
+}
+
+// issuerKey returns the key that signs the authorization responses.
+// If it is a signing key of an account, the account is returned as issuer account.
+func (r *NatsAuthCallout) issuerKey(ctx context.Context) (nkeys.KeyPair, string, error) {
+	kp, err := r.key(ctx, r.issuer, natsv1alpha1.KeyTypeAccount)
+	if err != nil {
+		return nil, "", err
+	}
+
+	public, err := kp.PublicKey()
+	if err != nil {
+		return nil, "", err
+	}
+
+	accounts := &natsv1alpha1.NatsAccountList{}
+	if err := r.List(ctx, accounts); err != nil {
+		return nil, "", err
+	}
+
+	for _, account := range accounts.Items {
+		if account.Status.JWT == "" || account.Status.PublicKey == public {
+			continue
+		}
+
+		claims, err := jwt.DecodeAccountClaims(account.Status.JWT)
+		if err != nil {
+			continue
+		}
+
+		if claims.SigningKeys.Contains(public) {
+			return kp, claims.Subject, nil
+		}
+	}
+
+	return kp, "", nil
+}
+
+// open decrypts an authorization request of a nats server that encrypts with its curve key.
+func (r *NatsAuthCallout) open(ctx context.Context, data []byte, serverXKey string) ([]byte, error) {
+	if serverXKey == "" {
+		return data, nil
+	}
+
+	if r.xkey.Name == "" {
+		return nil, fmt.Errorf("the request is encrypted by %s, but no curve key is configured", serverXKey)
+	}
+
+	kp, err := r.key(ctx, r.xkey, natsv1alpha1.KeyTypeCurve)
+	if err != nil {
+		return nil, err
+	}
+
+	return kp.Open(data, serverXKey)
+}
+
+// seal encrypts an authorization response for a nats server that encrypts with its curve key.
+func (r *NatsAuthCallout) seal(ctx context.Context, data []byte, serverXKey string) ([]byte, error) {
+	if serverXKey == "" {
+		return data, nil
+	}
+
+	kp, err := r.key(ctx, r.xkey, natsv1alpha1.KeyTypeCurve)
+	if err != nil {
+		return nil, err
+	}
+
+	return kp.Seal(data, serverXKey)
+}
+
+// key returns the key pair of a NatsKey of the type.
+func (r *NatsAuthCallout) key(ctx context.Context, name client.ObjectKey, keyType natsv1alpha1.KeyType) (nkeys.KeyPair, error) {
+	pk := &natsv1alpha1.NatsKey{}
+	if err := r.Get(ctx, name, pk); err != nil {
+		return nil, err
+	}
+
+	if pk.Spec.Type != keyType {
+		return nil, fmt.Errorf("key %s is of type %s, expected %s", name, pk.Spec.Type, keyType)
+	}
+
+	secret := &corev1.Secret{}
+	if err := r.secrets.Get(ctx, name, secret); err != nil {
+		return nil, err
+	}
+
+	return nkeys.FromSeed(secret.Data[natsv1alpha1.SecretSeedDataKey])
+}
+
+// splitServiceAccount splits the username of a ServiceAccount into its namespace and name.
+func splitServiceAccount(username string) (string, string, bool) {
+	rest, ok := strings.CutPrefix(username, serviceAccountUsernamePrefix)
+	if !ok {
+		return "", "", false
+	}
+
+	namespace, name, ok := strings.Cut(rest, ":")
+	if !ok || namespace == "" || name == "" {
+		return "", "", false
+	}
+
+	return namespace, name, true
+}
+
+// SetupWithManager sets up the auth callout with the Manager.
+func (r *NatsAuthCallout) SetupWithManager(mgr ctrl.Manager) error {
+	if len(r.audiences) == 0 {
+		return ErrNoAudiences
+	}
+
+	return mgr.Add(r)
+}
@@ -3,7 +3,9 @@ package controllers
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"math"
+	"net/url"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
@@ -11,17 +13,21 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	natsv1alpha1 "github.com/katallaxie/natz-operator/api/v1alpha1"
+	"github.com/katallaxie/natz-operator/pkg/config"
 	"github.com/katallaxie/natz-operator/pkg/status"
 	"github.com/katallaxie/pkg/conv"
 	"github.com/katallaxie/pkg/copyx"
 	"github.com/katallaxie/pkg/slices"
 	"github.com/katallaxie/pkg/utilx"
+	"github.com/samber/lo"
 )
 
 const (
@@ -129,6 +135,7 @@ func (r *NatsConfigReconciler) reconcileConfig(ctx context.Context, obj *natsv1a
 	if err != nil {
 		return err
 	}
+	cfg.Default()
 
 	cfg.SystemAccount = systemAccount.Status.PublicKey
 	cfg.Operator = operator.Status.JWT
@@ -136,20 +143,11 @@ func (r *NatsConfigReconciler) reconcileConfig(ctx context.Context, obj *natsv1a
 		systemAccount.Status.PublicKey: systemAccount.Status.JWT,
 	}
 
-	// for _, gateway := range obj.Spec.Gateways {
-	// 	gw := natsv1alpha1.GatewayEntry{
-	// 		Name: gateway.Name,
-	// 		URLS: []string{},
-	// 	}
-
-	// 	if utilx.Empty(config.Gateway) {
-	// 		config.Gateway = &natsv1alpha1.Gateway{}
-	// 	}
-
-	// 	config.Gateway.Gateways = append(config.Gateway.Gateways, gw)
-	// }
+	if err := r.reconcileGateways(ctx, obj, &cfg); err != nil {
+		return err
+	}
 
-	b, err := json.Marshal(cfg)
+	b, err := r.renderConfig(obj.Spec.Format, cfg)
 	if err != nil {
 		return err
 	}
@@ -157,12 +155,13 @@ func (r *NatsConfigReconciler) reconcileConfig(ctx context.Context, obj *natsv1a
 	c := &corev1.Secret{}
 	c.Namespace = obj.Namespace
 	c.Name = obj.Name
-	c.Type = natsv1alpha1.SecretConfigKey
-	c.Data = map[string][]byte{
-		natsv1alpha1.SecretConfigDataKey: b,
-	}
 
 	_, err = controllerutil.CreateOrUpdate(ctx, r.Client, c, func() error {
+		c.Type = natsv1alpha1.SecretConfigKey
+		c.Data = map[string][]byte{
+			natsv1alpha1.SecretConfigDataKey: b,
+		}
+
 		if !controllerutil.HasControllerReference(c) {
 			if err := controllerutil.SetControllerReference(obj, c, r.Scheme); err != nil {
 				return err
@@ -175,6 +174,103 @@ func (r *NatsConfigReconciler) reconcileConfig(ctx context.Context, obj *natsv1a
 	return err
 }
 
+func (r *NatsConfigReconciler) reconcileGateways(ctx context.Context, obj *natsv1alpha1.NatsConfig, cfg *natsv1alpha1.Config) error {
+	if len(obj.Spec.Gateways) == 0 {
+		return nil
+	}
+
+	gw := &natsv1alpha1.Gateway{}
+	if cfg.Gateway != nil {
+		gw = cfg.Gateway.DeepCopy()
+	}
+	gw.Name = utilx.Or(gw.Name, obj.Name)
+
+	for _, ref := range obj.Spec.Gateways {
+		gateway := &natsv1alpha1.NatsGateway{}
+		gatewayName := client.ObjectKey{
+			Namespace: utilx.Or(ref.Namespace, obj.Namespace),
+			Name:      ref.Name,
+		}
+
+		if err := r.Get(ctx, gatewayName, gateway); err != nil {
+			return err
+		}
+
+		entry, err := r.gatewayEntry(ctx, gateway)
+		if err != nil {
+			return err
+		}
+
+		gw.Gateways = lo.Reject(gw.Gateways, func(e natsv1alpha1.GatewayEntry, _ int) bool {
+			return e.Name == entry.Name
+		})
+		gw.Gateways = append(gw.Gateways, entry)
+	}
+
+	cfg.Gateway = gw
+
+	return nil
+}
+
+func (r *NatsConfigReconciler) gatewayEntry(ctx context.Context, gateway *natsv1alpha1.NatsGateway) (natsv1alpha1.GatewayEntry, error) {
+	entry := natsv1alpha1.GatewayEntry{
+		Name: gateway.GatewayName(),
+		TLS:  gateway.Spec.TLS.DeepCopy(),
+	}
+
+	u, err := url.Parse(gateway.Spec.URL)
+	if err != nil {
+		return entry, err
+	}
+
+	username, err := secretValue(ctx, r.Client, gateway.Namespace, gateway.Spec.Username)
+	if err != nil {
+		return entry, err
+	}
+
+	password, err := secretValue(ctx, r.Client, gateway.Namespace, gateway.Spec.Password)
+	if err != nil {
+		return entry, err
+	}
+
+	if username != "" {
+		u.User = url.UserPassword(username, password)
+	}
+
+	entry.URLS = []string{u.String()}
+
+	return entry, nil
+}
+
+// enqueueForSecret enqueues all configs of the gateways referencing the secret.
+func (r *NatsConfigReconciler) enqueueForSecret(ctx context.Context, obj client.Object) []reconcile.Request {
+	gateways := &natsv1alpha1.NatsGatewayList{}
+	if err := r.List(ctx, gateways, client.MatchingFields{secretRefIndex: client.ObjectKeyFromObject(obj).String()}); err != nil {
+		log.FromContext(ctx).Error(err, "listing referencing gateways", "index", secretRefIndex)
+		return nil
+	}
+
+	enqueue := enqueueReferencing(r.Client, &natsv1alpha1.NatsConfigList{}, gatewayRefIndex)
+
+	requests := []reconcile.Request{}
+	for i := range gateways.Items {
+		requests = append(requests, enqueue(ctx, &gateways.Items[i])...)
+	}
+
+	return requests
+}
+
+func (r *NatsConfigReconciler) renderConfig(format natsv1alpha1.ConfigFormat, cfg natsv1alpha1.Config) ([]byte, error) {
+	switch format {
+	case natsv1alpha1.ConfigFormatNative:
+		return config.MarshalNative(cfg)
+	case natsv1alpha1.ConfigFormatJSON, "":
+		return json.Marshal(cfg)
+	default:
+		return nil, fmt.Errorf("unsupported config format %q", format)
+	}
+}
+
 // IsCreating ...
 func (r *NatsConfigReconciler) IsCreating(obj *natsv1alpha1.NatsConfig) bool {
 	return utilx.Or(obj.Status.Conditions == nil, slices.Size(0, obj.Status.Conditions))
@@ -236,8 +332,72 @@ func (r *NatsConfigReconciler) ManageSuccess(ctx context.Context, obj *natsv1alp
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *NatsConfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	err := mgr.GetFieldIndexer().IndexField(context.Background(), &natsv1alpha1.NatsConfig{}, gatewayRefIndex, func(obj client.Object) []string {
+		config, ok := obj.(*natsv1alpha1.NatsConfig)
+		if !ok {
+			return nil
+		}
+
+		refs := make([]string, 0, len(config.Spec.Gateways))
+		for _, ref := range config.Spec.Gateways {
+			refs = append(refs, indexRef(utilx.Or(ref.Namespace, config.Namespace), ref.Name))
+		}
+
+		return refs
+	})
+	if err != nil {
+		return err
+	}
+
+	err = mgr.GetFieldIndexer().IndexField(context.Background(), &natsv1alpha1.NatsGateway{}, secretRefIndex, func(obj client.Object) []string {
+		gateway, ok := obj.(*natsv1alpha1.NatsGateway)
+		if !ok {
+			return nil
+		}
+
+		refs := []string{}
+		for _, src := range []natsv1alpha1.SecretValueFromSource{gateway.Spec.Username, gateway.Spec.Password} {
+			if src.SecretKeyRef != nil && src.SecretKeyRef.Name != "" {
+				refs = append(refs, indexRef(gateway.Namespace, src.SecretKeyRef.Name))
+			}
+		}
+
+		return refs
+	})
+	if err != nil {
+		return err
+	}
+
+	err = mgr.GetFieldIndexer().IndexField(context.Background(), &natsv1alpha1.NatsConfig{}, accountRefIndex, func(obj client.Object) []string {
+		config, ok := obj.(*natsv1alpha1.NatsConfig)
+		if !ok {
+			return nil
+		}
+
+		return []string{indexRef(config.Namespace, config.Spec.SystemAccountRef.Name)}
+	})
+	if err != nil {
+		return err
+	}
+
+	err = mgr.GetFieldIndexer().IndexField(context.Background(), &natsv1alpha1.NatsConfig{}, operatorRefIndex, func(obj client.Object) []string {
+		config, ok := obj.(*natsv1alpha1.NatsConfig)
+		if !ok {
+			return nil
+		}
+
+		return []string{indexRef(config.Namespace, config.Spec.OperatorRef.Name)}
+	})
+	if err != nil {
+		return err
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&natsv1alpha1.NatsConfig{}).
-		Owns(&corev1.ConfigMap{}).
+		Owns(&corev1.Secret{}).
+		Watches(&natsv1alpha1.NatsGateway{}, handler.EnqueueRequestsFromMapFunc(enqueueReferencing(r.Client, &natsv1alpha1.NatsConfigList{}, gatewayRefIndex))).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.enqueueForSecret)).
+		Watches(&natsv1alpha1.NatsAccount{}, handler.EnqueueRequestsFromMapFunc(enqueueReferencing(r.Client, &natsv1alpha1.NatsConfigList{}, accountRefIndex)), builder.WithPredicates(accountChanged())).
+		Watches(&natsv1alpha1.NatsOperator{}, handler.EnqueueRequestsFromMapFunc(enqueueReferencing(r.Client, &natsv1alpha1.NatsConfigList{}, operatorRefIndex)), builder.WithPredicates(operatorChanged())).
 		Complete(r)
 }
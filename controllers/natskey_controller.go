@@ -1,17 +1,23 @@
 package controllers
 
 import (
+	"bytes"
 	"context"
+	goerrors "errors"
 	"fmt"
 	"math"
 	"time"
 
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
@@ -20,15 +26,24 @@ import (
 	"github.com/katallaxie/pkg/conv"
 	"github.com/katallaxie/pkg/slices"
 	"github.com/katallaxie/pkg/utilx"
+	"github.com/nats-io/nkeys"
 	corev1 "k8s.io/api/core/v1"
 )
 
 const (
-	EventReasonKeyFailed       EventReason = "Failed"
-	EventReasonKeySynchronized EventReason = "Synchronized"
-	EventReasonStatusPaused    EventReason = "Paused"
+	EventReasonKeyFailed          EventReason = "Failed"
+	EventReasonKeySynchronized    EventReason = "Synchronized"
+	EventReasonStatusPaused       EventReason = "Paused"
+	EventReasonKeyRotated         EventReason = "Rotated"
+	EventReasonKeyImported        EventReason = "Imported"
+	EventReasonKeyRetired         EventReason = "Retired"
+	EventReasonKeyPostponed       EventReason = "Postponed"
+	EventReasonKeyRotationBlocked EventReason = "RotationBlocked"
 )
 
+// ErrIdentityKeyRotation is returned when the private key of an operator or account is to be rotated.
+var ErrIdentityKeyRotation = goerrors.New("identity key can not be rotated")
+
 // NatsPrivateKeyReconciler ...
 type NatsPrivateKeyReconciler struct {
 	client.Client
@@ -131,7 +146,16 @@ func (r *NatsPrivateKeyReconciler) reconcileSecret(ctx context.Context, sk *nats
 		Name:      sk.Name,
 	}
 
-	if err := r.Get(ctx, secretName, secret); !errors.IsNotFound(err) {
+	err := r.Get(ctx, secretName, secret)
+	if err == nil && sk.IsImported() {
+		return r.reconcileImport(ctx, sk, secret)
+	}
+
+	if err == nil {
+		return r.reconcileRotation(ctx, sk, secret)
+	}
+
+	if !errors.IsNotFound(err) {
 		return err
 	}
 
@@ -142,7 +166,7 @@ func (r *NatsPrivateKeyReconciler) reconcileSecret(ctx context.Context, sk *nats
 		natsv1alpha1.OwnerAnnotation: fmt.Sprintf("%s/%s", secret.Namespace, secret.Name),
 	}
 
-	keys, err := sk.Keys()
+	keys, err := r.keys(ctx, sk)
 	if err != nil {
 		return err
 	}
@@ -175,7 +199,267 @@ func (r *NatsPrivateKeyReconciler) reconcileSecret(ctx context.Context, sk *nats
 		r.Recorder.Event(sk, corev1.EventTypeNormal, conv.String(EventReasonKeySynchronized), "secret created or updated")
 	}
 
-	return nil
+	sk.Status.PublicKey = public
+	// a trigger that is already set on creation does not rotate the new key
+	sk.Status.LastRotationTrigger = sk.Annotations[natsv1alpha1.AnnotationRotateKey]
+
+	return r.Status().Update(ctx, sk)
+}
+
+// reconcileImport keeps the secret of the key in sync with the imported seed.
+// The imported seed replaces the seed of the secret, e.g. a previously generated seed.
+// Other data of the secret is kept, as the seed may be imported from the secret itself.
+func (r *NatsPrivateKeyReconciler) reconcileImport(ctx context.Context, sk *natsv1alpha1.NatsKey, secret *corev1.Secret) error {
+	keys, err := r.keys(ctx, sk)
+	if err != nil {
+		return err
+	}
+
+	seed, err := keys.Seed()
+	if err != nil {
+		return err
+	}
+
+	public, err := keys.PublicKey()
+	if err != nil {
+		return err
+	}
+
+	if bytes.Equal(secret.Data[natsv1alpha1.SecretSeedDataKey], seed) && sk.Status.PublicKey == public {
+		return nil
+	}
+
+	previous := conv.String(secret.Data[natsv1alpha1.SecretPublicKeyDataKey])
+
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+
+	secret.Data[natsv1alpha1.SecretSeedDataKey] = seed
+	secret.Data[natsv1alpha1.SecretPublicKeyDataKey] = []byte(public)
+
+	// imported keys have no grace period, the previous key is not kept
+	delete(secret.Data, natsv1alpha1.SecretPreviousSeedDataKey)
+	delete(secret.Data, natsv1alpha1.SecretPreviousPublicKeyDataKey)
+
+	if err := r.Update(ctx, secret); err != nil {
+		r.Recorder.Event(sk, corev1.EventTypeWarning, conv.String(EventReasonKeyFailed), "key import failed")
+		return err
+	}
+
+	sk.Status.PublicKey = public
+	sk.Status.GraceExpiry = metav1.Time{}
+
+	if previous != "" && previous != public {
+		r.Recorder.Event(sk, corev1.EventTypeWarning, conv.String(EventReasonKeyImported), fmt.Sprintf("key imported: %s replaces %s without a grace period", public, previous))
+	} else {
+		r.Recorder.Event(sk, corev1.EventTypeNormal, conv.String(EventReasonKeyImported), fmt.Sprintf("key imported: %s", public))
+	}
+
+	return r.Status().Update(ctx, sk)
+}
+
+// keys returns the imported keys of the key, or new keys if the seed is not imported.
+func (r *NatsPrivateKeyReconciler) keys(ctx context.Context, sk *natsv1alpha1.NatsKey) (nkeys.KeyPair, error) {
+	if !sk.IsImported() {
+		return sk.Keys()
+	}
+
+	seed, err := secretValue(ctx, r, sk.Namespace, sk.Spec.Seed)
+	if err != nil {
+		return nil, err
+	}
+
+	return sk.ImportKeys([]byte(seed))
+}
+
+func (r *NatsPrivateKeyReconciler) reconcileRotation(ctx context.Context, sk *natsv1alpha1.NatsKey, secret *corev1.Secret) error {
+	now := time.Now()
+
+	if err := r.reconcileGracePeriod(ctx, sk, secret, now); err != nil {
+		return err
+	}
+
+	var reason natsv1alpha1.KeyRotationReason
+	switch {
+	case sk.RotationRequested():
+		reason = natsv1alpha1.KeyRotationReasonManual
+	case !sk.NextRotation().IsZero() && !now.Before(sk.NextRotation()):
+		reason = natsv1alpha1.KeyRotationReasonScheduled
+	default:
+		return r.unblockRotation(ctx, sk)
+	}
+
+	// a rotation within the grace period would drop the previous key before it is retired,
+	// the rotation is postponed until the end of the grace period
+	if _, ok := secret.Data[natsv1alpha1.SecretPreviousSeedDataKey]; ok && now.Before(sk.Status.GraceExpiry.Time) {
+		r.Recorder.Event(sk, corev1.EventTypeNormal, conv.String(EventReasonKeyPostponed), fmt.Sprintf("key rotation postponed until %s", sk.Status.GraceExpiry.Format(time.RFC3339)))
+		return nil
+	}
+
+	identity, err := r.identityOf(ctx, sk)
+	if err != nil {
+		return err
+	}
+
+	// the public key of an operator or account is its identity, a rotation would create a new operator or account
+	if identity != "" {
+		err := fmt.Errorf("%w: the key is the private key of %s, only signing keys can be rotated", ErrIdentityKeyRotation, identity)
+		if meta.FindStatusCondition(sk.Status.Conditions, natsv1alpha1.ConditionTypeRotationBlocked) == nil {
+			r.Recorder.Event(sk, corev1.EventTypeWarning, conv.String(EventReasonKeyRotationBlocked), err.Error())
+		}
+
+		status.SetNatzKeyCondition(sk, status.NewKeyRotationBlockedCondition(sk, err))
+
+		return r.Status().Update(ctx, sk)
+	}
+
+	keys, err := sk.Keys()
+	if err != nil {
+		return err
+	}
+
+	seed, err := keys.Seed()
+	if err != nil {
+		return err
+	}
+
+	public, err := keys.PublicKey()
+	if err != nil {
+		return err
+	}
+
+	previous := conv.String(secret.Data[natsv1alpha1.SecretPublicKeyDataKey])
+
+	data := map[string][]byte{}
+	data[natsv1alpha1.SecretSeedDataKey] = seed
+	data[natsv1alpha1.SecretPublicKeyDataKey] = []byte(public)
+
+	if sk.GracePeriod() > 0 {
+		data[natsv1alpha1.SecretPreviousSeedDataKey] = secret.Data[natsv1alpha1.SecretSeedDataKey]
+		data[natsv1alpha1.SecretPreviousPublicKeyDataKey] = secret.Data[natsv1alpha1.SecretPublicKeyDataKey]
+	}
+
+	secret.Data = data
+
+	if err := r.Update(ctx, secret); err != nil {
+		r.Recorder.Event(sk, corev1.EventTypeWarning, conv.String(EventReasonKeyFailed), "key rotation failed")
+		return err
+	}
+
+	status.RemoveNatzKeyCondition(sk, natsv1alpha1.ConditionTypeRotationBlocked)
+
+	sk.Status.PublicKey = public
+	sk.Status.LastRotation = metav1.NewTime(now)
+	sk.Status.LastRotationTrigger = sk.Annotations[natsv1alpha1.AnnotationRotateKey]
+	sk.Status.GraceExpiry = metav1.Time{}
+
+	if sk.GracePeriod() > 0 {
+		sk.Status.GraceExpiry = metav1.NewTime(now.Add(sk.GracePeriod()))
+	}
+
+	sk.Status.Rotations = append(sk.Status.Rotations, natsv1alpha1.KeyRotationRecord{
+		PublicKey:         public,
+		PreviousPublicKey: previous,
+		Reason:            reason,
+		RotatedAt:         metav1.NewTime(now),
+	})
+
+	if len(sk.Status.Rotations) > sk.HistoryLimit() {
+		sk.Status.Rotations = sk.Status.Rotations[len(sk.Status.Rotations)-sk.HistoryLimit():]
+	}
+
+	r.Recorder.Event(sk, corev1.EventTypeNormal, conv.String(EventReasonKeyRotated), fmt.Sprintf("key rotated: %s", reason))
+
+	return r.Status().Update(ctx, sk)
+}
+
+// unblockRotation removes the condition of a blocked rotation, once no rotation is due.
+func (r *NatsPrivateKeyReconciler) unblockRotation(ctx context.Context, sk *natsv1alpha1.NatsKey) error {
+	if meta.FindStatusCondition(sk.Status.Conditions, natsv1alpha1.ConditionTypeRotationBlocked) == nil {
+		return nil
+	}
+
+	status.RemoveNatzKeyCondition(sk, natsv1alpha1.ConditionTypeRotationBlocked)
+
+	return r.Status().Update(ctx, sk)
+}
+
+// identityOf returns the operator or account that uses the key as its private key.
+func (r *NatsPrivateKeyReconciler) identityOf(ctx context.Context, sk *natsv1alpha1.NatsKey) (string, error) {
+	operators := &natsv1alpha1.NatsOperatorList{}
+	if err := r.List(ctx, operators, client.InNamespace(sk.Namespace)); err != nil {
+		return "", err
+	}
+
+	for _, operator := range operators.Items {
+		if operator.Spec.PrivateKey.Name == sk.Name {
+			return fmt.Sprintf("operator %s", operator.Name), nil
+		}
+	}
+
+	accounts := &natsv1alpha1.NatsAccountList{}
+	if err := r.List(ctx, accounts, client.InNamespace(sk.Namespace)); err != nil {
+		return "", err
+	}
+
+	for _, account := range accounts.Items {
+		if account.Spec.PrivateKey.Name == sk.Name {
+			return fmt.Sprintf("account %s", account.Name), nil
+		}
+	}
+
+	return "", nil
+}
+
+func (r *NatsPrivateKeyReconciler) reconcileGracePeriod(ctx context.Context, sk *natsv1alpha1.NatsKey, secret *corev1.Secret, now time.Time) error {
+	if _, ok := secret.Data[natsv1alpha1.SecretPreviousSeedDataKey]; !ok {
+		return nil
+	}
+
+	if now.Before(sk.Status.GraceExpiry.Time) {
+		return nil
+	}
+
+	previous := conv.String(secret.Data[natsv1alpha1.SecretPreviousPublicKeyDataKey])
+
+	delete(secret.Data, natsv1alpha1.SecretPreviousSeedDataKey)
+	delete(secret.Data, natsv1alpha1.SecretPreviousPublicKeyDataKey)
+
+	if err := r.Update(ctx, secret); err != nil {
+		return err
+	}
+
+	for i := range sk.Status.Rotations {
+		if sk.Status.Rotations[i].PreviousPublicKey == previous && sk.Status.Rotations[i].RetiredAt.IsZero() {
+			sk.Status.Rotations[i].RetiredAt = metav1.NewTime(now)
+		}
+	}
+
+	sk.Status.GraceExpiry = metav1.Time{}
+
+	r.Recorder.Event(sk, corev1.EventTypeNormal, conv.String(EventReasonKeyRetired), fmt.Sprintf("previous key retired: %s", previous))
+
+	// the status update enqueues the dependents, which are re-signed without the previous key
+	return r.Status().Update(ctx, sk)
+}
+
+// requeueAfter returns the duration until the next rotation or the end of the grace period.
+func (r *NatsPrivateKeyReconciler) requeueAfter(sk *natsv1alpha1.NatsKey) time.Duration {
+	now := time.Now()
+	after := time.Duration(0)
+
+	for _, t := range []time.Time{sk.NextRotation(), sk.Status.GraceExpiry.Time} {
+		if t.IsZero() || !t.After(now) {
+			continue
+		}
+
+		if d := t.Sub(now); after == 0 || d < after {
+			after = d
+		}
+	}
+
+	return after
 }
 
 func (r *NatsPrivateKeyReconciler) reconcileDelete(ctx context.Context, sk *natsv1alpha1.NatsKey) (ctrl.Result, error) {
@@ -235,7 +519,7 @@ func (r *NatsPrivateKeyReconciler) IsControlPaused(obj *natsv1alpha1.NatsKey) bo
 // ManageSuccess ...
 func (r *NatsPrivateKeyReconciler) ManageSuccess(ctx context.Context, obj *natsv1alpha1.NatsKey) (ctrl.Result, error) {
 	if r.IsSynchronized(obj) {
-		return ctrl.Result{}, nil
+		return ctrl.Result{RequeueAfter: r.requeueAfter(obj)}, nil
 	}
 
 	status.SetNatzKeyCondition(obj, status.NewKeySychronizedCondition(obj))
@@ -258,7 +542,7 @@ func (r *NatsPrivateKeyReconciler) ManageSuccess(ctx context.Context, obj *natsv
 
 	r.Recorder.Event(obj, corev1.EventTypeNormal, conv.String(EventReasonOperatorSynchronized), "synchronization successful")
 
-	return ctrl.Result{}, nil
+	return ctrl.Result{RequeueAfter: r.requeueAfter(obj)}, nil
 }
 
 // ManageError ...
@@ -281,9 +565,24 @@ func (r *NatsPrivateKeyReconciler) ManageError(ctx context.Context, obj *natsv1a
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *NatsPrivateKeyReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	err := mgr.GetFieldIndexer().IndexField(context.Background(), &natsv1alpha1.NatsKey{}, secretRefIndex, func(obj client.Object) []string {
+		sk, ok := obj.(*natsv1alpha1.NatsKey)
+		if !ok || !sk.IsImported() {
+			return nil
+		}
+
+		return []string{indexRef(sk.Namespace, sk.Spec.Seed.SecretKeyRef.Name)}
+	})
+	if err != nil {
+		return err
+	}
+
+	changed := predicate.Or(predicate.GenerationChangedPredicate{}, predicate.LabelChangedPredicate{}, predicate.AnnotationChangedPredicate{})
+
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&natsv1alpha1.NatsKey{}).
-		Owns(&corev1.Secret{}).
-		WithEventFilter(predicate.Or(predicate.GenerationChangedPredicate{}, predicate.LabelChangedPredicate{})).
+		For(&natsv1alpha1.NatsKey{}, builder.WithPredicates(changed)).
+		Owns(&corev1.Secret{}, builder.WithPredicates(changed)).
+		// the seeds of imported keys are updated in their secrets
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(enqueueReferencing(r.Client, &natsv1alpha1.NatsKeyList{}, secretRefIndex))).
 		Complete(r)
 }
@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"context"
+	goerrors "errors"
 	"fmt"
 	"math"
 	"time"
@@ -11,8 +12,10 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -110,7 +113,14 @@ func (r *NatsOperatorReconciler) reconcilePaused(ctx context.Context, sk *natsv1
 }
 
 func (r *NatsOperatorReconciler) reconcileResources(ctx context.Context, operator *natsv1alpha1.NatsOperator) error {
-	return r.reconcileOperator(ctx, operator)
+	if err := r.reconcileOperator(ctx, operator); err != nil {
+		return err
+	}
+
+	published, err := publish(ctx, r.Client, r.Scheme, operator, operator.Spec.Publish, operator.Status.Published, "operator", operator.Status.JWT, operator.Status.PublicKey)
+	operator.Status.Published = published
+
+	return err
 }
 
 func (r *NatsOperatorReconciler) reconcileOperator(ctx context.Context, obj *natsv1alpha1.NatsOperator) error {
@@ -140,12 +150,39 @@ func (r *NatsOperatorReconciler) reconcileOperator(ctx context.Context, obj *nat
 	}
 
 	token := jwt.NewOperatorClaims(public)
+	token.Name = obj.Name
+	token.AccountServerURL = obj.Spec.AccountServerURL
+	token.OperatorServiceURLs = obj.Spec.OperatorServiceURLs
+	token.StrictSigningKeyUsage = obj.Spec.StrictSigningKeyUsage
+	token.Tags.Add(obj.Spec.Tags...)
+
+	signingKeys, err := r.reconcileSigningKeys(ctx, obj)
+	if err != nil {
+		return err
+	}
+	token.SigningKeys.Add(signingKeys...)
+
+	if obj.Spec.EnableSystemAccount {
+		systemAccount, err := r.reconcileSystemAccount(ctx, obj)
+		if err != nil {
+			return err
+		}
+		token.SystemAccount = systemAccount
+	}
+
+	vr := jwt.CreateValidationResults()
+	token.Validate(vr)
+
+	if vr.IsBlocking(true) {
+		return goerrors.Join(vr.Errors()...)
+	}
+
 	jwt, err := token.Encode(sk)
 	if err != nil {
 		return err
 	}
 
-	obj.Status.JWT = jwt
+	obj.Status.JWT = reuseToken(obj.Status.JWT, jwt)
 	obj.Status.PublicKey = public
 
 	if !controllerutil.ContainsFinalizer(obj, natsv1alpha1.FinalizerName) {
@@ -155,6 +192,63 @@ func (r *NatsOperatorReconciler) reconcileOperator(ctx context.Context, obj *nat
 	return nil
 }
 
+func (r *NatsOperatorReconciler) reconcileSigningKeys(ctx context.Context, obj *natsv1alpha1.NatsOperator) ([]string, error) {
+	keys := []string{}
+
+	for _, key := range obj.Spec.SigningKeys {
+		sk := &corev1.Secret{}
+		skName := client.ObjectKey{
+			Namespace: obj.Namespace,
+			Name:      key.Name,
+		}
+
+		if err := r.Get(ctx, skName, sk); err != nil {
+			return nil, err
+		}
+
+		skSigner, err := nkeys.FromSeed(sk.Data[natsv1alpha1.SecretSeedDataKey])
+		if err != nil {
+			return nil, err
+		}
+
+		public, err := skSigner.PublicKey()
+		if err != nil {
+			return nil, err
+		}
+
+		if !nkeys.IsValidPublicOperatorKey(public) {
+			return nil, fmt.Errorf("signing key %s is not an operator key", key.Name)
+		}
+
+		keys = append(keys, public)
+
+		// the previous signing key is still trusted during the grace period of a rotation
+		if previous, ok := sk.Data[natsv1alpha1.SecretPreviousPublicKeyDataKey]; ok {
+			keys = append(keys, string(previous))
+		}
+	}
+
+	return keys, nil
+}
+
+func (r *NatsOperatorReconciler) reconcileSystemAccount(ctx context.Context, obj *natsv1alpha1.NatsOperator) (string, error) {
+	account := &natsv1alpha1.NatsAccount{}
+	accountName := client.ObjectKey{
+		Namespace: utilx.Or(obj.Spec.SystemAccountRef.Namespace, obj.Namespace),
+		Name:      obj.Spec.SystemAccountRef.Name,
+	}
+
+	if err := r.Get(ctx, accountName, account); err != nil {
+		return "", err
+	}
+
+	if account.Status.PublicKey == "" {
+		return "", fmt.Errorf("system account %s has no public key", account.Name)
+	}
+
+	return account.Status.PublicKey, nil
+}
+
 func (r *NatsOperatorReconciler) reconcileDelete(ctx context.Context, operator *natsv1alpha1.NatsOperator) (ctrl.Result, error) {
 	// Remove our finalizer from the list.
 	controllerutil.RemoveFinalizer(operator, natsv1alpha1.FinalizerName)
@@ -234,10 +328,41 @@ func (r *NatsOperatorReconciler) IsControlPaused(obj *natsv1alpha1.NatsOperator)
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *NatsOperatorReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	err := mgr.GetFieldIndexer().IndexField(context.Background(), &natsv1alpha1.NatsOperator{}, keyRefIndex, func(obj client.Object) []string {
+		operator, ok := obj.(*natsv1alpha1.NatsOperator)
+		if !ok {
+			return nil
+		}
+
+		refs := []string{indexRef(operator.Namespace, operator.Spec.PrivateKey.Name)}
+		for _, key := range operator.Spec.SigningKeys {
+			refs = append(refs, indexRef(operator.Namespace, key.Name))
+		}
+
+		return refs
+	})
+	if err != nil {
+		return err
+	}
+
+	err = mgr.GetFieldIndexer().IndexField(context.Background(), &natsv1alpha1.NatsOperator{}, accountRefIndex, func(obj client.Object) []string {
+		operator, ok := obj.(*natsv1alpha1.NatsOperator)
+		if !ok || operator.Spec.SystemAccountRef.Name == "" {
+			return nil
+		}
+
+		return []string{indexRef(utilx.Or(operator.Spec.SystemAccountRef.Namespace, operator.Namespace), operator.Spec.SystemAccountRef.Name)}
+	})
+	if err != nil {
+		return err
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&natsv1alpha1.NatsOperator{}).
+		For(&natsv1alpha1.NatsOperator{}, builder.WithPredicates(predicate.Or(predicate.GenerationChangedPredicate{}, predicate.LabelChangedPredicate{}))).
 		Owns(&natsv1alpha1.NatsAccount{}).
 		Owns(&corev1.Secret{}).
-		WithEventFilter(predicate.Or(predicate.GenerationChangedPredicate{}, predicate.LabelChangedPredicate{})).
+		Owns(&corev1.ConfigMap{}).
+		Watches(&natsv1alpha1.NatsKey{}, handler.EnqueueRequestsFromMapFunc(enqueueReferencing(r.Client, &natsv1alpha1.NatsOperatorList{}, keyRefIndex))).
+		Watches(&natsv1alpha1.NatsAccount{}, handler.EnqueueRequestsFromMapFunc(enqueueReferencing(r.Client, &natsv1alpha1.NatsOperatorList{}, accountRefIndex)), builder.WithPredicates(accountChanged())).
 		Complete(r)
 }
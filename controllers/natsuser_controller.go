@@ -2,22 +2,31 @@ package controllers
 
 import (
 	"context"
+	"encoding/json"
+	goerrors "errors"
 	"fmt"
 	"math"
+	"path"
+	"strings"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	natsv1alpha1 "github.com/katallaxie/natz-operator/api/v1alpha1"
 	"github.com/katallaxie/natz-operator/pkg/status"
+	"github.com/katallaxie/natz-operator/pkg/utils"
 	"github.com/katallaxie/pkg/conv"
 	"github.com/katallaxie/pkg/slices"
 	"github.com/katallaxie/pkg/utilx"
@@ -39,8 +48,22 @@ const (
 	EventReasonUserSecretCreateFailed    EventReason = "UserSecretCreateFailed"
 	EventReasonUserSynchronizeFailed     EventReason = "UserSynchronizeFailed"
 	EventReasonUserSynchronized          EventReason = "UserSynchronized"
+	EventReasonUserRevoked               EventReason = "UserRevoked"
+	EventReasonUserScoped                EventReason = "UserScoped"
 )
 
+// DefaultCredentialsMountPath is the default path the credentials secret of a user is mounted at.
+const DefaultCredentialsMountPath = "/etc/nats"
+
+// ErrForbiddenNamespace is returned when the account of a user does not allow users in its namespace.
+var ErrForbiddenNamespace = goerrors.New("forbidden namespace")
+
+// ErrNoServerURL is returned when the config of the credentials has no URL clients can connect to.
+var ErrNoServerURL = goerrors.New("no server url")
+
+// ErrUntrustedSigner is returned when the signer of a user is not trusted by its account.
+var ErrUntrustedSigner = goerrors.New("untrusted signer")
+
 // NatsUserReconciler reconciles a NatsUser object.
 type NatsUserReconciler struct {
 	client.Client
@@ -57,6 +80,7 @@ func NewNatsUserReconciler(mgr ctrl.Manager) *NatsUserReconciler {
 	}
 }
 
+//+kubebuilder:rbac:groups=,resources=namespaces,verbs=get;list;watch
 //+kubebuilder:rbac:groups=natz.katallaxie.dev,resources=natsusers,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=natz.katallaxie.dev,resources=natsusers/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=natz.katallaxie.dev,resources=natsusers/finalizers,verbs=update
@@ -89,6 +113,10 @@ func (r *NatsUserReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 }
 
 func (r *NatsUserReconciler) reconcileDelete(ctx context.Context, obj *natsv1alpha1.NatsUser) (ctrl.Result, error) {
+	if err := r.reconcileRevocation(ctx, obj); err != nil {
+		return ctrl.Result{}, err
+	}
+
 	// Remove our finalizer from the list.
 	controllerutil.RemoveFinalizer(obj, natsv1alpha1.FinalizerName)
 
@@ -103,11 +131,78 @@ func (r *NatsUserReconciler) reconcileDelete(ctx context.Context, obj *natsv1alp
 	return ctrl.Result{Requeue: true}, nil
 }
 
+// reconcileRevocation revokes the user in the account JWT.
+func (r *NatsUserReconciler) reconcileRevocation(ctx context.Context, user *natsv1alpha1.NatsUser) error {
+	if !controllerutil.ContainsFinalizer(user, natsv1alpha1.FinalizerName) {
+		return nil
+	}
+
+	revocations := []natsv1alpha1.UserRevocation{}
+
+	if user.Status.PublicKey != "" {
+		revocation := natsv1alpha1.UserRevocation{
+			Name:      client.ObjectKeyFromObject(user).String(),
+			PublicKey: user.Status.PublicKey,
+			RevokedAt: metav1.Now(),
+		}
+
+		if claims, err := jwt.DecodeUserClaims(user.Status.JWT); err == nil && claims.Expires > 0 {
+			revocation.Expiry = metav1.Unix(claims.Expires, 0)
+		}
+
+		revocations = append(revocations, revocation)
+	}
+
+	return r.revoke(ctx, user, revocations...)
+}
+
+// revoke revokes the credentials in the JWT of the account of the user.
+func (r *NatsUserReconciler) revoke(ctx context.Context, user *natsv1alpha1.NatsUser, revocations ...natsv1alpha1.UserRevocation) error {
+	if len(revocations) == 0 {
+		return nil
+	}
+
+	account := &natsv1alpha1.NatsAccount{}
+	if err := r.Get(ctx, accountName(user), account); err != nil {
+		// the user is gone with the account
+		return client.IgnoreNotFound(err)
+	}
+
+	// the revocations are written into the status of the account by the account controller
+	if err := addRevocations(ctx, r.Client, account, revocations, nil); err != nil {
+		return err
+	}
+
+	for _, revocation := range revocations {
+		r.Recorder.Eventf(account, corev1.EventTypeNormal, conv.String(EventReasonUserRevoked), "user %s revoked", revocation.Name)
+	}
+
+	return nil
+}
+
+func (r *NatsUserReconciler) reconcileFinalizer(ctx context.Context, user *natsv1alpha1.NatsUser) error {
+	if !controllerutil.ContainsFinalizer(user, natsv1alpha1.FinalizerName) {
+		controllerutil.AddFinalizer(user, natsv1alpha1.FinalizerName)
+		return r.Update(ctx, user)
+	}
+
+	return nil
+}
+
 func (r *NatsUserReconciler) reconcileResources(ctx context.Context, user *natsv1alpha1.NatsUser) error {
+	if err := r.reconcileFinalizer(ctx, user); err != nil {
+		return err
+	}
+
 	if err := r.reconcileUser(ctx, user); err != nil {
 		return err
 	}
 
+	// users bound to a ServiceAccount are issued to the pods by the auth callout
+	if user.Spec.ServiceAccountName != "" {
+		return nil
+	}
+
 	if err := r.reconcileCredentials(ctx, user); err != nil {
 		return err
 	}
@@ -127,24 +222,51 @@ func (r *NatsUserReconciler) reconcileCredentials(ctx context.Context, user *nat
 	}
 
 	secret := &corev1.Secret{}
-	secretName := client.ObjectKey{
-		Namespace: user.Namespace,
-		Name:      fmt.Sprintf("%s-credentils", user.Name),
-	}
+	secret.Name = fmt.Sprintf("%s-credentials", user.Name)
+	secret.Namespace = user.Namespace
 
-	if err := r.Get(ctx, secretName, secret); !errors.IsNotFound(err) {
+	url, err := r.credentialsURL(ctx, user)
+	if err != nil {
 		return err
 	}
 
-	secret.Name = fmt.Sprintf("%s-credentials", user.Name)
-	secret.Namespace = user.Namespace
-	secret.Type = natsv1alpha1.SecretUserCredentialsName
-	secret.Data = map[string][]byte{
+	seed := privateKey.Data[natsv1alpha1.SecretSeedDataKey]
+	creds := path.Join(utilx.Or(user.Spec.Credentials.MountPath, DefaultCredentialsMountPath), natsv1alpha1.SecretUserCredsKey)
+
+	data := map[string][]byte{
 		natsv1alpha1.SecretUserJWTKey:   []byte(user.Status.JWT),
-		natsv1alpha1.SecretUserCredsKey: []byte(fmt.Sprintf(ACCOUNT_TEMPLATE, user.Status.JWT, privateKey.Data[natsv1alpha1.SecretSeedDataKey])),
+		natsv1alpha1.SecretUserCredsKey: []byte(fmt.Sprintf(ACCOUNT_TEMPLATE, user.Status.JWT, seed)),
+	}
+
+	if user.Spec.Credentials.HasFormat(natsv1alpha1.CredentialsFormatContext) {
+		natsCtx, err := json.MarshalIndent(natsContext{
+			Description: fmt.Sprintf("NATS user %s", client.ObjectKeyFromObject(user)),
+			URL:         url,
+			Creds:       creds,
+		}, "", "  ")
+		if err != nil {
+			return err
+		}
+		data[natsv1alpha1.SecretUserContextKey] = natsCtx
 	}
 
-	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, secret, func() error {
+	if user.Spec.Credentials.HasFormat(natsv1alpha1.CredentialsFormatEnv) {
+		data[natsv1alpha1.SecretUserEnvURLKey] = []byte(url)
+		data[natsv1alpha1.SecretUserEnvCredsKey] = []byte(creds)
+	}
+
+	if user.Spec.Credentials.HasFormat(natsv1alpha1.CredentialsFormatNKey) {
+		data[natsv1alpha1.SecretUserNKeyKey] = seed
+	}
+
+	if user.Spec.Credentials.HasFormat(natsv1alpha1.CredentialsFormatURL) {
+		data[natsv1alpha1.SecretUserURLKey] = []byte(url)
+	}
+
+	_, err = controllerutil.CreateOrUpdate(ctx, r.Client, secret, func() error {
+		secret.Type = natsv1alpha1.SecretUserCredentialsName
+		secret.Data = data
+
 		return controllerutil.SetControllerReference(user, secret, r.Scheme)
 	})
 	if err != nil {
@@ -154,38 +276,79 @@ func (r *NatsUserReconciler) reconcileCredentials(ctx context.Context, user *nat
 	return nil
 }
 
+// natsContext is a context of the nats CLI.
+type natsContext struct {
+	Description string `json:"description"`
+	URL         string `json:"url,omitempty"`
+	Creds       string `json:"creds"`
+}
+
+// credentialsURL returns the connection URL of the config referenced by the credentials of the user.
+func (r *NatsUserReconciler) credentialsURL(ctx context.Context, user *natsv1alpha1.NatsUser) (string, error) {
+	ref := user.Spec.Credentials.ConfigRef
+	if ref == nil {
+		return "", nil
+	}
+
+	config := &natsv1alpha1.NatsConfig{}
+	configName := client.ObjectKey{
+		Namespace: utilx.Or(ref.Namespace, user.Namespace),
+		Name:      ref.Name,
+	}
+
+	if err := r.Get(ctx, configName, config); err != nil {
+		return "", err
+	}
+
+	urls := config.Spec.ServerURLs()
+	if len(urls) == 0 {
+		return "", fmt.Errorf("%w: config %s has neither clientURLs nor a client_advertise address", ErrNoServerURL, configName)
+	}
+
+	return strings.Join(urls, ","), nil
+}
+
 //nolint:gocyclo
 func (r *NatsUserReconciler) reconcileUser(ctx context.Context, user *natsv1alpha1.NatsUser) error {
-	sk := &natsv1alpha1.NatsKey{}
-	skName := client.ObjectKey{
-		Namespace: user.Namespace,
-		Name:      user.Spec.SignerKeyRef.Name,
+	skAccount := &natsv1alpha1.NatsAccount{}
+	if err := r.Get(ctx, accountName(user), skAccount); err != nil {
+		return err
 	}
 
-	if err := r.Get(ctx, skName, sk); err != nil {
+	if err := r.reconcileAccess(ctx, user, skAccount); err != nil {
 		return err
 	}
 
 	skSecret := &corev1.Secret{}
-	skSecretName := client.ObjectKey{
-		Namespace: sk.Namespace,
-		Name:      sk.Name,
+	if err := r.Get(ctx, signerKeyName(user, skAccount), skSecret); err != nil {
+		return err
 	}
 
-	if err := r.Get(ctx, skSecretName, skSecret); err != nil {
+	signerKp, err := nkeys.FromSeed(skSecret.Data[natsv1alpha1.SecretSeedDataKey])
+	if err != nil {
 		return err
 	}
 
-	skAccount := &natsv1alpha1.NatsAccount{}
-	skAccountName := client.ObjectKey{
-		Namespace: user.Namespace,
-		Name:      user.Spec.AccountRef.Name,
+	trust, err := userTrust(user, skAccount, signerKp)
+	status.SetNatzUserCondition(user, status.NewUserTrustedCondition(user, err))
+	if err != nil {
+		return err
 	}
 
-	if err := r.Get(ctx, skAccountName, skAccount); err != nil {
+	// users of a scoped signing key are constrained by the template of the account
+	if trust.scoped && !(&jwt.UserClaims{User: user.Spec.ToNatsJWT()}).HasEmptyPermissions() {
+		r.Recorder.Event(user, corev1.EventTypeWarning, conv.String(EventReasonUserScoped), "permissions and limits are ignored, the signing key is scoped")
+	}
+
+	expiry, err := r.userExpiry(user)
+	if err != nil {
 		return err
 	}
 
+	if user.Spec.ServiceAccountName != "" {
+		return r.reconcileServiceAccount(ctx, user)
+	}
+
 	pk := &natsv1alpha1.NatsKey{}
 	pkName := client.ObjectKey{
 		Namespace: user.Namespace,
@@ -216,21 +379,39 @@ func (r *NatsUserReconciler) reconcileUser(ctx context.Context, user *natsv1alph
 		return err
 	}
 
-	signerKp, err := nkeys.FromSeed(skSecret.Data[natsv1alpha1.SecretSeedDataKey])
+	token, err := userClaims(user, user.Name, public, trust, expiry)
 	if err != nil {
 		return err
 	}
 
-	token := jwt.NewUserClaims(public)
-	token.User = user.Spec.ToNatsJWT()
-	// by default sigining key is the account public key
-	token.IssuerAccount = skAccount.Status.PublicKey
-
 	t, err := token.Encode(signerKp)
 	if err != nil {
 		return err
 	}
-	user.Status.JWT = t
+
+	t = reuseToken(user.Status.JWT, t)
+	if t != user.Status.JWT || user.Status.ObservedGeneration != user.Generation {
+		if !expiry.Equal(user.Status.Expiry.Time) || user.Status.ObservedGeneration != user.Generation {
+			user.Status.RenewAt = metav1.Time{}
+
+			if !expiry.IsZero() {
+				user.Status.RenewAt = metav1.NewTime(user.Spec.RenewAt(time.Unix(token.IssuedAt, 0), expiry))
+			}
+		}
+
+		user.Status.JWT = t
+		user.Status.PublicKey = public
+		user.Status.Expiry = metav1.NewTime(expiry)
+		user.Status.ObservedGeneration = user.Generation
+		user.Status.LastUpdate = metav1.Now()
+
+		// persist the re-signed token, a synchronized user is not updated on success
+		if r.IsSynchronized(user) {
+			if err := r.Status().Update(ctx, user); err != nil {
+				return err
+			}
+		}
+	}
 
 	if !controllerutil.HasControllerReference(user) {
 		if err := controllerutil.SetControllerReference(user, pk, r.Scheme); err != nil {
@@ -241,6 +422,192 @@ func (r *NatsUserReconciler) reconcileUser(ctx context.Context, user *natsv1alph
 	return nil
 }
 
+// reconcileServiceAccount observes a user bound to a ServiceAccount.
+// The user has no key of its own, the pods are issued short-lived JWTs by the auth callout.
+func (r *NatsUserReconciler) reconcileServiceAccount(ctx context.Context, user *natsv1alpha1.NatsUser) error {
+	if user.Status.ObservedGeneration == user.Generation {
+		return nil
+	}
+
+	user.Status.ObservedGeneration = user.Generation
+	user.Status.LastUpdate = metav1.Now()
+
+	// a synchronized user is not updated on success
+	if r.IsSynchronized(user) {
+		return r.Status().Update(ctx, user)
+	}
+
+	return nil
+}
+
+// userClaims returns the claims of a user with the name and the public key, and the tags in addition to the tags of the user.
+// The templates in the permissions are expanded, unless they are constrained by a scoped signing key.
+func userClaims(user *natsv1alpha1.NatsUser, name, public string, trust signerTrust, expiry time.Time, tags ...string) (*jwt.UserClaims, error) {
+	token := jwt.NewUserClaims(public)
+	token.Name = name
+	token.User = user.Spec.ToNatsJWT()
+	token.Tags.Add(tags...)
+	// users signed by a signing key carry the account they are issued for
+	token.IssuerAccount = trust.issuerAccount
+
+	if trust.scoped {
+		token.SetScoped(true)
+	} else if err := expandPermissions(&token.Permissions, name, public, token.Tags); err != nil {
+		return nil, err
+	}
+
+	if !expiry.IsZero() {
+		token.Expires = expiry.Unix()
+	}
+
+	return token, nil
+}
+
+// expandPermissions expands the templates of the subjects of the permissions.
+func expandPermissions(p *jwt.Permissions, name, public string, tags jwt.TagList) error {
+	for _, subjects := range []*jwt.StringList{&p.Pub.Allow, &p.Pub.Deny, &p.Sub.Allow, &p.Sub.Deny} {
+		expanded, err := utils.ExpandTemplates(*subjects, name, public, tags)
+		if err != nil {
+			return err
+		}
+
+		*subjects = expanded
+	}
+
+	return nil
+}
+
+// userExpiry returns the expiry of the user JWT.
+// The expiry of the issued JWT is kept until it is due for renewal or the spec changed.
+func (r *NatsUserReconciler) userExpiry(user *natsv1alpha1.NatsUser) (time.Time, error) {
+	if user.Status.ObservedGeneration == user.Generation && !user.Status.Expiry.IsZero() && time.Now().Before(user.Status.RenewAt.Time) {
+		return user.Status.Expiry.Time, nil
+	}
+
+	expiry, err := utils.ParseExpiry(user.Spec.Expiry)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if expiry == 0 {
+		return time.Time{}, nil
+	}
+
+	if expiry <= time.Now().Unix() {
+		return time.Time{}, fmt.Errorf("expiry %q of user %s is in the past", user.Spec.Expiry, user.Name)
+	}
+
+	return time.Unix(expiry, 0), nil
+}
+
+// renewAfter returns the duration after which the user JWT is renewed.
+// It is zero if there is nothing to renew.
+func (r *NatsUserReconciler) renewAfter(obj *natsv1alpha1.NatsUser) time.Duration {
+	if obj.Status.RenewAt.IsZero() {
+		return 0
+	}
+
+	return max(time.Until(obj.Status.RenewAt.Time), 0)
+}
+
+// reconcileAccess verifies that the account allows users in the namespace of the user.
+func (r *NatsUserReconciler) reconcileAccess(ctx context.Context, user *natsv1alpha1.NatsUser, account *natsv1alpha1.NatsAccount) error {
+	ns := &corev1.Namespace{}
+	if err := r.Get(ctx, client.ObjectKey{Name: user.Namespace}, ns); err != nil {
+		return err
+	}
+
+	allowed, err := account.AllowsUserNamespace(ns.Name, ns.Labels)
+	if err != nil {
+		return err
+	}
+
+	if allowed {
+		status.RemoveNatzUserCondition(user, natsv1alpha1.ConditionTypeForbidden)
+		return nil
+	}
+
+	err = fmt.Errorf("%w: account %s does not allow users in namespace %s", ErrForbiddenNamespace, client.ObjectKeyFromObject(account), user.Namespace)
+	status.SetNatzUserCondition(user, status.NewUserForbiddenCondition(user, err))
+	r.Recorder.Event(user, corev1.EventTypeWarning, conv.String(EventReasonAccountAccessFailed), err.Error())
+
+	return err
+}
+
+// accountName returns the name of the account of the user, it defaults to the namespace of the user.
+func accountName(user *natsv1alpha1.NatsUser) client.ObjectKey {
+	return client.ObjectKey{
+		Namespace: utilx.Or(user.Spec.AccountRef.Namespace, user.Namespace),
+		Name:      user.Spec.AccountRef.Name,
+	}
+}
+
+// signerKeyName returns the name of the key that signs the user.
+// Without a signer key the first scoped signing key of the account is used, or the account key itself.
+// The keys of an account are in the namespace of the account, so is the signer key.
+func signerKeyName(user *natsv1alpha1.NatsUser, account *natsv1alpha1.NatsAccount) client.ObjectKey {
+	if user.Spec.SignerKeyRef.Name != "" {
+		return client.ObjectKey{Namespace: account.Namespace, Name: user.Spec.SignerKeyRef.Name}
+	}
+
+	if len(account.Spec.ScopedSigningKeys) > 0 {
+		return client.ObjectKey{Namespace: account.Namespace, Name: account.Spec.ScopedSigningKeys[0].Name}
+	}
+
+	return client.ObjectKey{Namespace: account.Namespace, Name: account.Spec.PrivateKey.Name}
+}
+
+// signerTrust is the trust of an account in the signer of a user.
+type signerTrust struct {
+	issuerAccount string
+	scoped        bool
+}
+
+// userTrust verifies that the account trusts the signer of the user.
+// Users outside the namespace of the account have to be signed by a scoped signing key,
+// so they are constrained by the account.
+func userTrust(user *natsv1alpha1.NatsUser, account *natsv1alpha1.NatsAccount, signer nkeys.KeyPair) (signerTrust, error) {
+	trust, err := accountTrust(account, signer)
+	if err != nil {
+		return signerTrust{}, err
+	}
+
+	if user.Namespace != account.Namespace && !trust.scoped {
+		return signerTrust{}, fmt.Errorf("%w: users in namespace %s have to be signed by a scoped signing key of account %s", ErrUntrustedSigner, user.Namespace, client.ObjectKeyFromObject(account))
+	}
+
+	return trust, nil
+}
+
+// accountTrust verifies that the signer is the account key or one of the signing keys of the account.
+func accountTrust(account *natsv1alpha1.NatsAccount, signer nkeys.KeyPair) (signerTrust, error) {
+	if account.Status.JWT == "" {
+		return signerTrust{}, fmt.Errorf("account %s is not synchronized", account.Name)
+	}
+
+	claims, err := jwt.DecodeAccountClaims(account.Status.JWT)
+	if err != nil {
+		return signerTrust{}, err
+	}
+
+	pk, err := signer.PublicKey()
+	if err != nil {
+		return signerTrust{}, err
+	}
+
+	if pk == claims.Subject {
+		return signerTrust{}, nil
+	}
+
+	if !claims.SigningKeys.Contains(pk) {
+		return signerTrust{}, fmt.Errorf("%w: %s is not a signing key of account %s", ErrUntrustedSigner, pk, account.Name)
+	}
+
+	scope, ok := claims.SigningKeys.GetScope(pk)
+
+	return signerTrust{issuerAccount: claims.Subject, scoped: ok && scope != nil}, nil
+}
+
 // IsCreating ...
 func (r *NatsUserReconciler) IsCreating(obj *natsv1alpha1.NatsUser) bool {
 	return utilx.Or(obj.Status.Conditions == nil, slices.Size(0, obj.Status.Conditions))
@@ -276,7 +643,7 @@ func (r *NatsUserReconciler) ManageError(ctx context.Context, obj *natsv1alpha1.
 // ManageSuccess ...
 func (r *NatsUserReconciler) ManageSuccess(ctx context.Context, obj *natsv1alpha1.NatsUser) (ctrl.Result, error) {
 	if r.IsSynchronized(obj) {
-		return ctrl.Result{}, nil
+		return ctrl.Result{RequeueAfter: r.renewAfter(obj)}, nil
 	}
 
 	obj.Status.Phase = natsv1alpha1.UserPhaseSynchronized
@@ -296,13 +663,57 @@ func (r *NatsUserReconciler) ManageSuccess(ctx context.Context, obj *natsv1alpha
 
 	r.Recorder.Event(obj, corev1.EventTypeNormal, conv.String(EventReasonUserSynchronized), "user synchronized")
 
-	return ctrl.Result{}, nil
+	return ctrl.Result{RequeueAfter: r.renewAfter(obj)}, nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *NatsUserReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	err := mgr.GetFieldIndexer().IndexField(context.Background(), &natsv1alpha1.NatsUser{}, keyRefIndex, func(obj client.Object) []string {
+		user, ok := obj.(*natsv1alpha1.NatsUser)
+		if !ok {
+			return nil
+		}
+
+		refs := []string{indexRef(user.Namespace, user.Spec.PrivateKey.Name)}
+		if user.Spec.SignerKeyRef.Name != "" {
+			refs = append(refs, indexRef(accountName(user).Namespace, user.Spec.SignerKeyRef.Name))
+		}
+
+		return refs
+	})
+	if err != nil {
+		return err
+	}
+
+	err = mgr.GetFieldIndexer().IndexField(context.Background(), &natsv1alpha1.NatsUser{}, accountRefIndex, func(obj client.Object) []string {
+		user, ok := obj.(*natsv1alpha1.NatsUser)
+		if !ok {
+			return nil
+		}
+
+		return []string{accountName(user).String()}
+	})
+	if err != nil {
+		return err
+	}
+
+	err = mgr.GetFieldIndexer().IndexField(context.Background(), &natsv1alpha1.NatsUser{}, configRefIndex, func(obj client.Object) []string {
+		user, ok := obj.(*natsv1alpha1.NatsUser)
+		if !ok || user.Spec.Credentials.ConfigRef == nil {
+			return nil
+		}
+
+		return []string{indexRef(utilx.Or(user.Spec.Credentials.ConfigRef.Namespace, user.Namespace), user.Spec.Credentials.ConfigRef.Name)}
+	})
+	if err != nil {
+		return err
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&natsv1alpha1.NatsUser{}).
 		Owns(&corev1.Secret{}).
+		Watches(&natsv1alpha1.NatsKey{}, handler.EnqueueRequestsFromMapFunc(enqueueReferencing(r.Client, &natsv1alpha1.NatsUserList{}, keyRefIndex))).
+		Watches(&natsv1alpha1.NatsAccount{}, handler.EnqueueRequestsFromMapFunc(enqueueReferencing(r.Client, &natsv1alpha1.NatsUserList{}, accountRefIndex)), builder.WithPredicates(predicate.Or(accountChanged(), predicate.GenerationChangedPredicate{}))).
+		Watches(&natsv1alpha1.NatsConfig{}, handler.EnqueueRequestsFromMapFunc(enqueueReferencing(r.Client, &natsv1alpha1.NatsUserList{}, configRefIndex)), builder.WithPredicates(predicate.GenerationChangedPredicate{})).
 		Complete(r)
 }
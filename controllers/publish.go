@@ -0,0 +1,109 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	natsv1alpha1 "github.com/katallaxie/natz-operator/api/v1alpha1"
+
+	"github.com/katallaxie/pkg/utilx"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+//+kubebuilder:rbac:groups=,resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+
+// publish materialises the JWT and the public key of the owner into an owned Secret or ConfigMap.
+// The previously published resource is deleted if the kind or the name changed, or publish was removed.
+// It returns the reference to the published resource, which is recorded in the status of the owner.
+func publish(ctx context.Context, c client.Client, scheme *runtime.Scheme, owner client.Object, spec *natsv1alpha1.PublishSpec, published *natsv1alpha1.PublishedRef, kind, jwt, publicKey string) (*natsv1alpha1.PublishedRef, error) {
+	if spec == nil {
+		return nil, unpublish(ctx, c, owner, published)
+	}
+
+	if jwt == "" {
+		return published, nil
+	}
+
+	ref := &natsv1alpha1.PublishedRef{
+		Kind: utilx.Or(spec.Kind, natsv1alpha1.PublishKindSecret),
+		Name: utilx.Or(spec.Name, fmt.Sprintf("%s-jwt", owner.GetName())),
+	}
+	jwtKey := utilx.Or(spec.JWTKey, fmt.Sprintf("%s.jwt", kind))
+	publicKeyKey := utilx.Or(spec.PublicKeyKey, natsv1alpha1.SecretPublicKeyDataKey)
+
+	var obj client.Object
+	var mutate controllerutil.MutateFn
+
+	if ref.Kind == natsv1alpha1.PublishKindConfigMap {
+		cm := &corev1.ConfigMap{}
+		obj = cm
+		mutate = func() error {
+			cm.Data = map[string]string{
+				jwtKey:       jwt,
+				publicKeyKey: publicKey,
+			}
+
+			return controllerutil.SetControllerReference(owner, cm, scheme)
+		}
+	} else {
+		secret := &corev1.Secret{}
+		obj = secret
+		mutate = func() error {
+			secret.Type = natsv1alpha1.SecretJWTName
+			secret.Data = map[string][]byte{
+				jwtKey:       []byte(jwt),
+				publicKeyKey: []byte(publicKey),
+			}
+
+			return controllerutil.SetControllerReference(owner, secret, scheme)
+		}
+	}
+
+	obj.SetName(ref.Name)
+	obj.SetNamespace(owner.GetNamespace())
+
+	if _, err := controllerutil.CreateOrUpdate(ctx, c, obj, mutate); err != nil {
+		return published, err
+	}
+
+	if published != nil && *published != *ref {
+		if err := unpublish(ctx, c, owner, published); err != nil {
+			return published, err
+		}
+	}
+
+	return ref, nil
+}
+
+// unpublish deletes the previously published resource, if it is still controlled by the owner.
+func unpublish(ctx context.Context, c client.Client, owner client.Object, published *natsv1alpha1.PublishedRef) error {
+	if published == nil {
+		return nil
+	}
+
+	var obj client.Object = &corev1.Secret{}
+	if published.Kind == natsv1alpha1.PublishKindConfigMap {
+		obj = &corev1.ConfigMap{}
+	}
+
+	err := c.Get(ctx, client.ObjectKey{Namespace: owner.GetNamespace(), Name: published.Name}, obj)
+	if errors.IsNotFound(err) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	// the resource was taken over, e.g. by another resource publishing into it
+	if !metav1.IsControlledBy(obj, owner) {
+		return nil
+	}
+
+	return client.IgnoreNotFound(c.Delete(ctx, obj))
+}
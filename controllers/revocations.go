@@ -0,0 +1,169 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	natsv1alpha1 "github.com/katallaxie/natz-operator/api/v1alpha1"
+
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// The revocations of an account are kept in its revocations ConfigMap.
+// The ConfigMap is not owned by the account, so that the revocations survive the loss of the status
+// or a re-created account, and users with JWTs that do not expire stay revoked.
+// The user and activation controllers add revocations to the ConfigMap,
+// only the account controller writes the revocations into the status of the account.
+
+// addRevocations adds the revocations to the revocations ConfigMap of the account.
+func addRevocations(ctx context.Context, c client.Client, account *natsv1alpha1.NatsAccount, users []natsv1alpha1.UserRevocation, activations []natsv1alpha1.ActivationRevocation) error {
+	return retry.OnError(retry.DefaultRetry, isRevocationsConflict, func() error {
+		cm, revoked, err := revocations(ctx, c, account)
+		if err != nil {
+			return err
+		}
+
+		for _, u := range users {
+			revoked.RevokeUser(u)
+		}
+
+		for _, a := range activations {
+			revoked.RevokeActivation(a)
+		}
+
+		return writeRevocations(ctx, c, account, cm, revoked)
+	})
+}
+
+// syncRevocations merges the revocations ConfigMap of the account into its status,
+// prunes the expired revocations and writes them back into the ConfigMap.
+func syncRevocations(ctx context.Context, c client.Client, account *natsv1alpha1.NatsAccount, now time.Time) error {
+	return retry.OnError(retry.DefaultRetry, isRevocationsConflict, func() error {
+		cm, revoked, err := revocations(ctx, c, account)
+		if err != nil {
+			return err
+		}
+
+		account.RestoreRevocations(revoked.Status.RevokedUsers, revoked.Status.RevokedActivations)
+
+		// revocations of deleted users are kept until their JWTs expired
+		account.PruneRevokedUsers(now)
+		// revocations of deleted activations are kept until the activations expired
+		account.PruneRevokedActivations(now)
+
+		revoked.Status.RevokedUsers = account.Status.RevokedUsers
+		revoked.Status.RevokedActivations = account.Status.RevokedActivations
+
+		return writeRevocations(ctx, c, account, cm, revoked)
+	})
+}
+
+// isRevocationsConflict returns true if the revocations ConfigMap was written concurrently.
+func isRevocationsConflict(err error) bool {
+	return errors.IsConflict(err) || errors.IsAlreadyExists(err)
+}
+
+// revocations reads the revocations ConfigMap of the account.
+// The revocations are returned in the status of an account, the ConfigMap is new if it does not exist.
+func revocations(ctx context.Context, c client.Client, account *natsv1alpha1.NatsAccount) (*corev1.ConfigMap, *natsv1alpha1.NatsAccount, error) {
+	revoked := &natsv1alpha1.NatsAccount{}
+
+	cm := &corev1.ConfigMap{}
+	err := c.Get(ctx, client.ObjectKey{Namespace: account.Namespace, Name: account.RevocationsName()}, cm)
+	if errors.IsNotFound(err) {
+		cm.Namespace = account.Namespace
+		cm.Name = account.RevocationsName()
+
+		return cm, revoked, nil
+	}
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if v, ok := cm.Data[natsv1alpha1.ConfigMapRevokedUsersKey]; ok {
+		if err := json.Unmarshal([]byte(v), &revoked.Status.RevokedUsers); err != nil {
+			return nil, nil, fmt.Errorf("decoding revoked users of %s: %w", client.ObjectKeyFromObject(cm), err)
+		}
+	}
+
+	if v, ok := cm.Data[natsv1alpha1.ConfigMapRevokedActivationsKey]; ok {
+		if err := json.Unmarshal([]byte(v), &revoked.Status.RevokedActivations); err != nil {
+			return nil, nil, fmt.Errorf("decoding revoked activations of %s: %w", client.ObjectKeyFromObject(cm), err)
+		}
+	}
+
+	return cm, revoked, nil
+}
+
+// writeRevocations writes the revocations into the ConfigMap of the account.
+// Empty lists are written, so that pruned revocations are not restored,
+// a missing ConfigMap is only created for revocations.
+func writeRevocations(ctx context.Context, c client.Client, account *natsv1alpha1.NatsAccount, cm *corev1.ConfigMap, revoked *natsv1alpha1.NatsAccount) error {
+	users, err := json.Marshal(lo.Ternary(revoked.Status.RevokedUsers == nil, []natsv1alpha1.UserRevocation{}, revoked.Status.RevokedUsers))
+	if err != nil {
+		return err
+	}
+
+	activations, err := json.Marshal(lo.Ternary(revoked.Status.RevokedActivations == nil, []natsv1alpha1.ActivationRevocation{}, revoked.Status.RevokedActivations))
+	if err != nil {
+		return err
+	}
+
+	data := map[string]string{
+		natsv1alpha1.ConfigMapRevokedUsersKey:       string(users),
+		natsv1alpha1.ConfigMapRevokedActivationsKey: string(activations),
+	}
+
+	if cm.ResourceVersion == "" {
+		if len(revoked.Status.RevokedUsers) == 0 && len(revoked.Status.RevokedActivations) == 0 {
+			return nil
+		}
+
+		cm.Annotations = map[string]string{
+			natsv1alpha1.OwnerAnnotation: client.ObjectKeyFromObject(account).String(),
+		}
+		cm.Data = data
+
+		return c.Create(ctx, cm)
+	}
+
+	if cm.Data[natsv1alpha1.ConfigMapRevokedUsersKey] == data[natsv1alpha1.ConfigMapRevokedUsersKey] &&
+		cm.Data[natsv1alpha1.ConfigMapRevokedActivationsKey] == data[natsv1alpha1.ConfigMapRevokedActivationsKey] {
+		return nil
+	}
+
+	cm.Data = data
+
+	return c.Update(ctx, cm)
+}
+
+// enqueueForRevocations maps a revocations ConfigMap to its account.
+func enqueueForRevocations(_ context.Context, obj client.Object) []reconcile.Request {
+	owner, ok := obj.GetAnnotations()[natsv1alpha1.OwnerAnnotation]
+	if !ok {
+		return nil
+	}
+
+	namespace, name, ok := strings.Cut(owner, string(types.Separator))
+	if !ok || namespace != obj.GetNamespace() {
+		return nil
+	}
+
+	account := &natsv1alpha1.NatsAccount{}
+	account.Name = name
+	if obj.GetName() != account.RevocationsName() {
+		return nil
+	}
+
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: namespace, Name: name}}}
+}
@@ -0,0 +1,31 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	natsv1alpha1 "github.com/katallaxie/natz-operator/api/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// secretValue returns the value referenced by the source in the namespace.
+// An empty source returns an empty value.
+func secretValue(ctx context.Context, c client.Reader, namespace string, src natsv1alpha1.SecretValueFromSource) (string, error) {
+	if src.SecretKeyRef == nil || src.SecretKeyRef.Name == "" {
+		return "", nil
+	}
+
+	secret := &corev1.Secret{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: src.SecretKeyRef.Name}, secret); err != nil {
+		return "", err
+	}
+
+	v, ok := secret.Data[src.SecretKeyRef.Key]
+	if !ok {
+		return "", fmt.Errorf("key %q not found in secret %s/%s", src.SecretKeyRef.Key, namespace, src.SecretKeyRef.Name)
+	}
+
+	return string(v), nil
+}
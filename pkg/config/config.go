@@ -2,6 +2,7 @@ package config
 
 import (
 	"encoding/json"
+	"time"
 
 	"github.com/katallaxie/pkg/cast"
 	"github.com/pkg/errors"
@@ -206,16 +207,27 @@ func (c *Property) GetBlock() isBlock_Block {
 	return nil
 }
 
-type isBlock_Block interface{}
+type isBlock_Block interface {
+	isBlock_Block()
+}
 
 // Block_Object represents an object of a configuration block.
-type Block_Object struct{}
+type Block_Object struct {
+	// Properties ...
+	Properties []*Property
+}
 
 // Block_Array represents an array of a configuration block.
-type Block_Array struct{}
+type Block_Array struct {
+	// Blocks ...
+	Blocks []Block
+}
 
-// Block_Include ...
-type Block_Include struct{}
+// Block_Include represents an include of another configuration file.
+type Block_Include struct {
+	// Path ...
+	Path string
+}
 
 // Block_String ...
 type Block_String struct {
@@ -223,6 +235,36 @@ type Block_String struct {
 	Value string
 }
 
+// Block_Number ...
+type Block_Number struct {
+	// Value ...
+	Value int64
+}
+
+// Block_Float ...
+type Block_Float struct {
+	// Value ...
+	Value float64
+}
+
+// Block_Bool ...
+type Block_Bool struct {
+	// Value ...
+	Value bool
+}
+
+// Block_Size represents a size in bytes (e.g. 10GB).
+type Block_Size struct {
+	// Value ...
+	Value int64
+}
+
+// Block_Duration represents a duration (e.g. "2m").
+type Block_Duration struct {
+	// Value ...
+	Value time.Duration
+}
+
 func (b *Block_Object) isBlock_Block() {}
 
 func (b *Block_Array) isBlock_Block() {}
@@ -230,3 +272,13 @@ func (b *Block_Array) isBlock_Block() {}
 func (b *Block_String) isBlock_Block() {}
 
 func (b *Block_Include) isBlock_Block() {}
+
+func (b *Block_Number) isBlock_Block() {}
+
+func (b *Block_Float) isBlock_Block() {}
+
+func (b *Block_Bool) isBlock_Block() {}
+
+func (b *Block_Size) isBlock_Block() {}
+
+func (b *Block_Duration) isBlock_Block() {}
@@ -0,0 +1,353 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultIndent is the default indentation of the encoder.
+const DefaultIndent = "  "
+
+var bareKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_\-]*$`)
+
+// sizeKeys are the keys of properties that are rendered as size literals.
+var sizeKeys = map[string]struct{}{
+	"max_file_store":   {},
+	"max_memory_store": {},
+	"max_payload":      {},
+	"max_pending":      {},
+}
+
+var sizeUnits = []struct {
+	suffix string
+	size   int64
+}{
+	{"TB", 1 << 40},
+	{"GB", 1 << 30},
+	{"MB", 1 << 20},
+	{"KB", 1 << 10},
+}
+
+// Encoder writes configuration properties in the NATS configuration format.
+type Encoder struct {
+	w      io.Writer
+	indent string
+}
+
+// NewEncoder returns a new encoder that writes to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w, indent: DefaultIndent}
+}
+
+// SetIndent sets the indentation of nested blocks.
+func (e *Encoder) SetIndent(indent string) {
+	e.indent = indent
+}
+
+// Encode writes the properties as the top level of a configuration file.
+func (e *Encoder) Encode(props ...*Property) error {
+	var b strings.Builder
+
+	for _, p := range props {
+		if err := e.writeProperty(&b, p, 0); err != nil {
+			return err
+		}
+	}
+
+	_, err := io.WriteString(e.w, b.String())
+
+	return errors.WithStack(err)
+}
+
+// MarshalNative returns the NATS configuration format of v.
+// The value is converted by its JSON representation, so the json tags of v apply.
+func MarshalNative(v any) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	props, err := FromJSON(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := NewEncoder(&buf).Encode(props...); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// FromJSON converts a JSON object to configuration properties.
+// The order of the keys is preserved, null values are omitted.
+func FromJSON(data []byte) ([]*Property, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	t, err := dec.Token()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if t != json.Delim('{') {
+		return nil, errors.Errorf("config: expected object, got %v", t)
+	}
+
+	return decodeObject(dec)
+}
+
+func decodeObject(dec *json.Decoder) ([]*Property, error) {
+	props := []*Property{}
+
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		key, ok := t.(string)
+		if !ok {
+			return nil, errors.Errorf("config: expected key, got %v", t)
+		}
+
+		block, err := decodeValue(dec, key)
+		if err != nil {
+			return nil, err
+		}
+
+		if block == nil {
+			continue
+		}
+
+		props = append(props, &Property{Name: key, Block: block})
+	}
+
+	// consume the closing delimiter
+	if _, err := dec.Token(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return props, nil
+}
+
+func decodeValue(dec *json.Decoder, key string) (Block, error) {
+	t, err := dec.Token()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	switch v := t.(type) {
+	case json.Delim:
+		switch v {
+		case '{':
+			props, err := decodeObject(dec)
+			if err != nil {
+				return nil, err
+			}
+
+			return &Block_Object{Properties: props}, nil
+		case '[':
+			blocks := []Block{}
+
+			for dec.More() {
+				block, err := decodeValue(dec, key)
+				if err != nil {
+					return nil, err
+				}
+
+				if block != nil {
+					blocks = append(blocks, block)
+				}
+			}
+
+			if _, err := dec.Token(); err != nil {
+				return nil, errors.WithStack(err)
+			}
+
+			return &Block_Array{Blocks: blocks}, nil
+		}
+	case string:
+		return &Block_String{Value: v}, nil
+	case bool:
+		return &Block_Bool{Value: v}, nil
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			if _, ok := sizeKeys[key]; ok {
+				return &Block_Size{Value: i}, nil
+			}
+
+			return &Block_Number{Value: i}, nil
+		}
+
+		f, err := v.Float64()
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		return &Block_Float{Value: f}, nil
+	case nil:
+		return nil, nil
+	}
+
+	return nil, errors.Errorf("config: unexpected token %v", t)
+}
+
+func (e *Encoder) writeProperty(b *strings.Builder, p *Property, depth int) error {
+	if p == nil || p.Block == nil {
+		return nil
+	}
+
+	b.WriteString(strings.Repeat(e.indent, depth))
+
+	switch block := p.Block.(type) {
+	case *Block_Include:
+		b.WriteString("include ")
+		b.WriteString(quote(block.Path))
+		b.WriteByte('\n')
+
+		return nil
+	case *Block_Object, *Block_Array:
+		b.WriteString(formatKey(p.Name))
+		b.WriteByte(' ')
+	default:
+		b.WriteString(formatKey(p.Name))
+		b.WriteString(": ")
+	}
+
+	if err := e.writeValue(b, p.Block, depth); err != nil {
+		return err
+	}
+
+	b.WriteByte('\n')
+
+	return nil
+}
+
+func (e *Encoder) writeValue(b *strings.Builder, block isBlock_Block, depth int) error {
+	switch block := block.(type) {
+	case *Block_Object:
+		if len(block.Properties) == 0 {
+			b.WriteString("{}")
+			return nil
+		}
+
+		b.WriteString("{\n")
+
+		for _, p := range block.Properties {
+			if err := e.writeProperty(b, p, depth+1); err != nil {
+				return err
+			}
+		}
+
+		b.WriteString(strings.Repeat(e.indent, depth))
+		b.WriteByte('}')
+	case *Block_Array:
+		if len(block.Blocks) == 0 {
+			b.WriteString("[]")
+			return nil
+		}
+
+		b.WriteString("[\n")
+
+		for _, v := range block.Blocks {
+			b.WriteString(strings.Repeat(e.indent, depth+1))
+
+			if err := e.writeValue(b, v, depth+1); err != nil {
+				return err
+			}
+
+			b.WriteByte('\n')
+		}
+
+		b.WriteString(strings.Repeat(e.indent, depth))
+		b.WriteByte(']')
+	case *Block_String:
+		b.WriteString(quote(block.Value))
+	case *Block_Number:
+		b.WriteString(strconv.FormatInt(block.Value, 10))
+	case *Block_Float:
+		b.WriteString(strconv.FormatFloat(block.Value, 'f', -1, 64))
+	case *Block_Bool:
+		b.WriteString(strconv.FormatBool(block.Value))
+	case *Block_Size:
+		b.WriteString(formatSize(block.Value))
+	case *Block_Duration:
+		b.WriteString(quote(formatDuration(block.Value)))
+	default:
+		return errors.Errorf("config: unsupported block %T", block)
+	}
+
+	return nil
+}
+
+func formatKey(key string) string {
+	if bareKey.MatchString(key) {
+		return key
+	}
+
+	return quote(key)
+}
+
+// formatSize returns the largest exact size literal (e.g. 10GB) of v.
+func formatSize(v int64) string {
+	for _, u := range sizeUnits {
+		if v != 0 && v%u.size == 0 {
+			return fmt.Sprintf("%d%s", v/u.size, u.suffix)
+		}
+	}
+
+	return strconv.FormatInt(v, 10)
+}
+
+// formatDuration returns the shortest representation of d (e.g. 2m instead of 2m0s).
+func formatDuration(d time.Duration) string {
+	s := d.String()
+
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+
+	return s
+}
+
+// quote returns a double quoted string with the escapes of the NATS configuration format.
+func quote(s string) string {
+	var b strings.Builder
+
+	b.WriteByte('"')
+
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	b.WriteByte('"')
+
+	return b.String()
+}
@@ -0,0 +1,123 @@
+package config_test
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/katallaxie/natz-operator/pkg/config"
+	"github.com/katallaxie/pkg/cast"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func golden(t *testing.T, name string, got []byte) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name+".conf")
+
+	if *update {
+		require.NoError(t, os.WriteFile(path, got, 0o600))
+	}
+
+	want, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, string(want), string(got))
+}
+
+func TestFromJSON(t *testing.T) {
+	t.Parallel()
+
+	tests := []string{"default", "server"}
+
+	for _, name := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			data, err := os.ReadFile(filepath.Join("testdata", name+".json"))
+			require.NoError(t, err)
+
+			props, err := config.FromJSON(data)
+			require.NoError(t, err)
+
+			var buf bytes.Buffer
+			err = config.NewEncoder(&buf).Encode(props...)
+			require.NoError(t, err)
+
+			golden(t, name, buf.Bytes())
+		})
+	}
+}
+
+func TestEncode(t *testing.T) {
+	t.Parallel()
+
+	props := []*config.Property{
+		{Block: &config.Block_Include{Path: "./accounts.conf"}},
+		{Name: "server_name", Block: &config.Block_String{Value: "nats-0"}},
+		{Name: "max_payload", Block: &config.Block_Size{Value: 8 << 20}},
+		{Name: "max_pending", Block: &config.Block_Size{Value: 1000}},
+		{Name: "ping_interval", Block: &config.Block_Duration{Value: 2 * time.Minute}},
+		{Name: "write_deadline", Block: &config.Block_Duration{Value: 1500 * time.Millisecond}},
+		{Name: "debug", Block: &config.Block_Bool{Value: false}},
+		{Name: "cluster", Block: &config.Block_Object{
+			Properties: []*config.Property{
+				{Name: "name", Block: &config.Block_String{Value: "natz"}},
+				{Name: "routes", Block: &config.Block_Array{
+					Blocks: []config.Block{
+						&config.Block_String{Value: "nats://nats-1:6222"},
+						&config.Block_String{Value: "nats://nats-2:6222"},
+					},
+				}},
+				{Name: "authorization", Block: &config.Block_Object{}},
+			},
+		}},
+		{Name: "accounts", Block: &config.Block_Object{
+			Properties: []*config.Property{
+				{Name: "$SYS", Block: &config.Block_Object{
+					Properties: []*config.Property{
+						{Name: "users", Block: &config.Block_Array{
+							Blocks: []config.Block{
+								&config.Block_Object{
+									Properties: []*config.Property{
+										{Name: "user", Block: &config.Block_String{Value: "admin"}},
+										{Name: "password", Block: &config.Block_String{Value: "$2a$11\\n"}},
+									},
+								},
+							},
+						}},
+					},
+				}},
+			},
+		}},
+		{Name: "tags", Block: &config.Block_Array{}},
+	}
+
+	var buf bytes.Buffer
+	err := config.NewEncoder(&buf).Encode(props...)
+	require.NoError(t, err)
+
+	golden(t, "blocks", buf.Bytes())
+}
+
+func TestMarshalNative(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.Default()
+	cfg.HTTPPort = cast.Ptr(8222)
+
+	b, err := config.MarshalNative(cfg)
+	require.NoError(t, err)
+	require.Equal(t, "host: \"0.0.0.0\"\nport: 4222\nhttp_port: 8222\n", string(b))
+}
+
+func TestFromJSONInvalid(t *testing.T) {
+	t.Parallel()
+
+	_, err := config.FromJSON([]byte(`["host"]`))
+	require.Error(t, err)
+}
@@ -0,0 +1,610 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+// MaxIncludeDepth is the maximum depth of nested includes.
+const MaxIncludeDepth = 10
+
+// sizeSuffixes are the multipliers of the size suffixes of numbers (e.g. 1KB or 1k).
+var sizeSuffixes = map[string]int64{
+	"k": 1000, "kb": 1 << 10, "ki": 1 << 10, "kib": 1 << 10,
+	"m": 1000 * 1000, "mb": 1 << 20, "mi": 1 << 20, "mib": 1 << 20,
+	"g": 1000 * 1000 * 1000, "gb": 1 << 30, "gi": 1 << 30, "gib": 1 << 30,
+	"t": 1000 * 1000 * 1000 * 1000, "tb": 1 << 40, "ti": 1 << 40, "tib": 1 << 40,
+}
+
+// bcryptPrefixes are prefixes of bcrypt hashes that are not variable references.
+var bcryptPrefixes = []string{"2a$", "2b$", "2x$", "2y$"}
+
+type parser struct {
+	src    []rune
+	pos    int
+	line   int
+	dir    string
+	depth  int
+	scopes []*Block_Object
+}
+
+// Parse parses a configuration in the NATS configuration format.
+// Includes are resolved relative to the working directory.
+func Parse(data []byte) ([]*Property, error) {
+	return parse(data, ".")
+}
+
+// ParseFile parses a configuration file in the NATS configuration format.
+// Includes are resolved relative to the directory of the file.
+func ParseFile(path string) ([]*Property, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return parse(data, filepath.Dir(path))
+}
+
+// LoadFile parses a configuration file into a Config.
+func LoadFile(path string) (*Config, error) {
+	props, err := ParseFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := ToJSON(props)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := New()
+	if err := cfg.Unmarshal(data); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// UnmarshalNative parses a configuration in the NATS configuration format into the Config.
+func (c *Config) UnmarshalNative(data []byte) error {
+	props, err := Parse(data)
+	if err != nil {
+		return err
+	}
+
+	b, err := ToJSON(props)
+	if err != nil {
+		return err
+	}
+
+	return c.Unmarshal(b)
+}
+
+func parse(data []byte, dir string) ([]*Property, error) {
+	root := &Block_Object{Properties: []*Property{}}
+
+	p := &parser{src: []rune(string(data)), line: 1, dir: dir, scopes: []*Block_Object{root}}
+	if err := p.parseItems(0); err != nil {
+		return nil, err
+	}
+
+	return root.Properties, nil
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return errors.Errorf("config: line %d: %s", p.line, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *parser) peek() rune {
+	if p.eof() {
+		return 0
+	}
+
+	return p.src[p.pos]
+}
+
+func (p *parser) next() rune {
+	r := p.peek()
+	p.pos++
+
+	if r == '\n' {
+		p.line++
+	}
+
+	return r
+}
+
+// skip skips whitespace, comments and item separators.
+// Newlines are only skipped if nl is true.
+func (p *parser) skip(nl bool) {
+	for !p.eof() {
+		r := p.peek()
+
+		switch {
+		case r == '\n' || r == ',' || r == ';':
+			if !nl {
+				return
+			}
+
+			p.next()
+		case unicode.IsSpace(r):
+			p.next()
+		case r == '#' || (r == '/' && p.pos+1 < len(p.src) && p.src[p.pos+1] == '/'):
+			for !p.eof() && p.peek() != '\n' {
+				p.next()
+			}
+		default:
+			return
+		}
+	}
+}
+
+// parseItems parses the items of the current scope until the closing rune (0 for the end of the input).
+func (p *parser) parseItems(end rune) error {
+	for {
+		p.skip(true)
+
+		if p.eof() {
+			if end != 0 {
+				return p.errorf("unexpected end of input, expected %q", end)
+			}
+
+			return nil
+		}
+
+		if end != 0 && p.peek() == end {
+			p.next()
+			return nil
+		}
+
+		if err := p.parseItem(); err != nil {
+			return err
+		}
+	}
+}
+
+func (p *parser) parseItem() error {
+	quoted := p.peek() == '"' || p.peek() == '\''
+
+	key, err := p.parseKey()
+	if err != nil {
+		return err
+	}
+
+	scope := p.scopes[len(p.scopes)-1]
+
+	if key == "include" && !quoted {
+		p.skip(false)
+
+		path, err := p.parseIncludePath()
+		if err != nil {
+			return err
+		}
+
+		return p.include(path)
+	}
+
+	p.skip(false)
+
+	if r := p.peek(); r == ':' || r == '=' {
+		p.next()
+		p.skip(false)
+	}
+
+	block, err := p.parseValue()
+	if err != nil {
+		return err
+	}
+
+	scope.Properties = append(scope.Properties, &Property{Name: key, Block: block})
+
+	return nil
+}
+
+func (p *parser) parseKey() (string, error) {
+	switch p.peek() {
+	case '"':
+		return p.parseQuoted()
+	case '\'':
+		return p.parseRaw()
+	}
+
+	start := p.pos
+	for !p.eof() {
+		r := p.peek()
+		if unicode.IsSpace(r) || r == ':' || r == '=' || r == '{' || r == '[' {
+			break
+		}
+
+		p.next()
+	}
+
+	if start == p.pos {
+		return "", p.errorf("expected key, got %q", p.peek())
+	}
+
+	return string(p.src[start:p.pos]), nil
+}
+
+func (p *parser) parseIncludePath() (string, error) {
+	switch p.peek() {
+	case '"':
+		return p.parseQuoted()
+	case '\'':
+		return p.parseRaw()
+	}
+
+	path := p.parseBare()
+	if path == "" {
+		return "", p.errorf("expected include path")
+	}
+
+	return path, nil
+}
+
+func (p *parser) include(path string) error {
+	if p.depth >= MaxIncludeDepth {
+		return p.errorf("include %q exceeds the maximum depth of %d", path, MaxIncludeDepth)
+	}
+
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(p.dir, path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	child := &parser{
+		src:    []rune(string(data)),
+		line:   1,
+		dir:    filepath.Dir(path),
+		depth:  p.depth + 1,
+		scopes: p.scopes,
+	}
+
+	if err := child.parseItems(0); err != nil {
+		return errors.Wrapf(err, "include %s", path)
+	}
+
+	return nil
+}
+
+func (p *parser) parseValue() (Block, error) {
+	if p.eof() {
+		return nil, p.errorf("unexpected end of input, expected value")
+	}
+
+	switch p.peek() {
+	case '{':
+		p.next()
+
+		obj := &Block_Object{Properties: []*Property{}}
+
+		p.scopes = append(p.scopes, obj)
+		err := p.parseItems('}')
+		p.scopes = p.scopes[:len(p.scopes)-1]
+
+		if err != nil {
+			return nil, err
+		}
+
+		return obj, nil
+	case '[':
+		p.next()
+
+		arr := &Block_Array{Blocks: []Block{}}
+
+		for {
+			p.skip(true)
+
+			if p.eof() {
+				return nil, p.errorf("unexpected end of input, expected ']'")
+			}
+
+			if p.peek() == ']' {
+				p.next()
+				return arr, nil
+			}
+
+			block, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+
+			arr.Blocks = append(arr.Blocks, block)
+		}
+	case '"':
+		s, err := p.parseQuoted()
+		if err != nil {
+			return nil, err
+		}
+
+		return &Block_String{Value: s}, nil
+	case '\'':
+		s, err := p.parseRaw()
+		if err != nil {
+			return nil, err
+		}
+
+		return &Block_String{Value: s}, nil
+	case '$':
+		p.next()
+
+		return p.parseVariable()
+	}
+
+	token := p.parseBare()
+	if token == "" {
+		return nil, p.errorf("unexpected %q, expected value", p.peek())
+	}
+
+	return scalar(token), nil
+}
+
+// parseVariable resolves a variable reference from the enclosing scopes or the environment.
+func (p *parser) parseVariable() (Block, error) {
+	name := p.parseBare()
+
+	for _, prefix := range bcryptPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return &Block_String{Value: "$" + name}, nil
+		}
+	}
+
+	if name == "" {
+		return nil, p.errorf("expected variable name")
+	}
+
+	for i := len(p.scopes) - 1; i >= 0; i-- {
+		props := p.scopes[i].Properties
+		for j := len(props) - 1; j >= 0; j-- {
+			if props[j].Name == name {
+				return props[j].Block, nil
+			}
+		}
+	}
+
+	env, ok := os.LookupEnv(name)
+	if !ok {
+		return nil, p.errorf("variable reference for %q can not be found", name)
+	}
+
+	// environment variables are parsed as values
+	v := &parser{src: []rune(env), line: p.line, dir: p.dir, depth: p.depth, scopes: p.scopes}
+
+	block, err := v.parseValue()
+	if v.skip(true); err == nil && v.eof() {
+		return block, nil
+	}
+
+	return &Block_String{Value: env}, nil
+}
+
+func (p *parser) parseBare() string {
+	start := p.pos
+
+	for !p.eof() {
+		r := p.peek()
+		if unicode.IsSpace(r) || r == ',' || r == ';' || r == '}' || r == ']' || r == '#' {
+			break
+		}
+
+		p.next()
+	}
+
+	return string(p.src[start:p.pos])
+}
+
+// parseQuoted parses a double quoted string with escapes.
+func (p *parser) parseQuoted() (string, error) {
+	p.next()
+
+	var b strings.Builder
+
+	for {
+		if p.eof() {
+			return "", p.errorf("unterminated string")
+		}
+
+		r := p.next()
+
+		switch r {
+		case '"':
+			return b.String(), nil
+		case '\\':
+			if p.eof() {
+				return "", p.errorf("unterminated string")
+			}
+
+			switch e := p.next(); e {
+			case 'n':
+				b.WriteRune('\n')
+			case 't':
+				b.WriteRune('\t')
+			case 'r':
+				b.WriteRune('\r')
+			case '"', '\\':
+				b.WriteRune(e)
+			case 'x':
+				if p.pos+2 > len(p.src) {
+					return "", p.errorf("invalid escape")
+				}
+
+				v, err := strconv.ParseUint(string(p.src[p.pos:p.pos+2]), 16, 8)
+				if err != nil {
+					return "", p.errorf("invalid escape")
+				}
+
+				p.pos += 2
+				b.WriteByte(byte(v))
+			default:
+				return "", p.errorf("invalid escape %q", e)
+			}
+		default:
+			b.WriteRune(r)
+		}
+	}
+}
+
+// parseRaw parses a single quoted string without escapes.
+func (p *parser) parseRaw() (string, error) {
+	p.next()
+
+	start := p.pos
+	for !p.eof() && p.peek() != '\'' {
+		p.next()
+	}
+
+	if p.eof() {
+		return "", p.errorf("unterminated string")
+	}
+
+	s := string(p.src[start:p.pos])
+	p.next()
+
+	return s, nil
+}
+
+// scalar converts a bare token to a number, size, bool or string block.
+func scalar(token string) Block {
+	if i, err := strconv.ParseInt(token, 10, 64); err == nil {
+		return &Block_Number{Value: i}
+	}
+
+	if b, ok := parseBool(token); ok {
+		return &Block_Bool{Value: b}
+	}
+
+	if i := strings.IndexFunc(token, func(r rune) bool { return !unicode.IsDigit(r) }); i > 0 {
+		if m, ok := sizeSuffixes[strings.ToLower(token[i:])]; ok {
+			if v, err := strconv.ParseInt(token[:i], 10, 64); err == nil {
+				return &Block_Size{Value: v * m}
+			}
+		}
+	}
+
+	if f, err := strconv.ParseFloat(token, 64); err == nil {
+		return &Block_Float{Value: f}
+	}
+
+	return &Block_String{Value: token}
+}
+
+func parseBool(token string) (bool, bool) {
+	switch strings.ToLower(token) {
+	case "true", "yes", "on":
+		return true, true
+	case "false", "no", "off":
+		return false, true
+	}
+
+	return false, false
+}
+
+// ToJSON converts configuration properties to a JSON object.
+// The order of the properties is preserved.
+func ToJSON(props []*Property) ([]byte, error) {
+	var buf bytes.Buffer
+
+	if err := writeJSON(&buf, &Block_Object{Properties: props}); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func writeJSON(buf *bytes.Buffer, block isBlock_Block) error {
+	var v any
+
+	switch block := block.(type) {
+	case *Block_Object:
+		buf.WriteByte('{')
+
+		first := true
+
+		for _, p := range block.Properties {
+			if p == nil || p.Block == nil {
+				continue
+			}
+
+			if inc, ok := p.Block.(*Block_Include); ok {
+				return errors.Errorf("config: unresolved include %q", inc.Path)
+			}
+
+			if !first {
+				buf.WriteByte(',')
+			}
+
+			first = false
+
+			key, err := json.Marshal(p.Name)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+
+			buf.Write(key)
+			buf.WriteByte(':')
+
+			if err := writeJSON(buf, p.Block); err != nil {
+				return err
+			}
+		}
+
+		buf.WriteByte('}')
+
+		return nil
+	case *Block_Array:
+		buf.WriteByte('[')
+
+		for i, b := range block.Blocks {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+
+			if err := writeJSON(buf, b); err != nil {
+				return err
+			}
+		}
+
+		buf.WriteByte(']')
+
+		return nil
+	case *Block_String:
+		v = block.Value
+	case *Block_Number:
+		v = block.Value
+	case *Block_Float:
+		v = block.Value
+	case *Block_Bool:
+		v = block.Value
+	case *Block_Size:
+		v = block.Value
+	case *Block_Duration:
+		v = formatDuration(block.Value)
+	default:
+		return errors.Errorf("config: unsupported block %T", block)
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	buf.Write(b)
+
+	return nil
+}
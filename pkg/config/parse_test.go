@@ -0,0 +1,93 @@
+package config_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/katallaxie/natz-operator/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFile(t *testing.T) {
+	t.Setenv("SERVER_NAME", "nats-0")
+	t.Setenv("HTTP_PORT", "8222")
+
+	props, err := config.ParseFile(filepath.Join("testdata", "parse", "main.conf"))
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	err = config.NewEncoder(&buf).Encode(props...)
+	require.NoError(t, err)
+
+	golden(t, "parse", buf.Bytes())
+}
+
+func TestParseRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	tests := []string{"default", "server", "parse"}
+
+	for _, name := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			data, err := os.ReadFile(filepath.Join("testdata", name+".conf"))
+			require.NoError(t, err)
+
+			props, err := config.Parse(data)
+			require.NoError(t, err)
+
+			var buf bytes.Buffer
+			err = config.NewEncoder(&buf).Encode(props...)
+			require.NoError(t, err)
+			require.Equal(t, string(data), buf.String())
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		in   string
+	}{
+		{name: "unknown variable", in: "port: $NATZ_UNKNOWN_VARIABLE"},
+		{name: "unterminated string", in: `host: "localhost`},
+		{name: "unterminated block", in: "cluster { name: natz"},
+		{name: "missing include", in: "include ./missing.conf"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := config.Parse([]byte(tc.in))
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestUnmarshalNative(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.New()
+	err := cfg.UnmarshalNative([]byte("host: localhost\nport = 4223\ntls { cert_file: \"/etc/nats/tls.crt\", verify: true }"))
+	require.NoError(t, err)
+	require.Equal(t, "localhost", *cfg.Host)
+	require.Equal(t, 4223, *cfg.Port)
+	require.Equal(t, "/etc/nats/tls.crt", cfg.TLS.CertFile)
+	require.True(t, cfg.TLS.Verify)
+}
+
+func TestLoadFile(t *testing.T) {
+	t.Setenv("SERVER_NAME", "nats-0")
+	t.Setenv("HTTP_PORT", "8222")
+
+	cfg, err := config.LoadFile(filepath.Join("testdata", "parse", "main.conf"))
+	require.NoError(t, err)
+	require.Equal(t, 4222, *cfg.Port)
+	require.Equal(t, 8222, *cfg.HTTPPort)
+}
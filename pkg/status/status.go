@@ -5,8 +5,7 @@ import (
 
 	natsv1alpha1 "github.com/katallaxie/natz-operator/api/v1alpha1"
 
-	"github.com/katallaxie/pkg/slices"
-	"github.com/katallaxie/pkg/utilx"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -21,15 +20,17 @@ func NewCondition(conditionType string, conditionStatus metav1.ConditionStatus,
 	}
 }
 
-// SetCondition ...
+// SetCondition sets the condition, an existing condition of the type is replaced.
+// The transition time of an existing condition is kept if its status does not change.
 func SetCondition(condition metav1.Condition, conditions ...metav1.Condition) []metav1.Condition {
-	return utilx.IfElse(
-		slices.Any(func(cond metav1.Condition) bool {
-			return cond.Type == condition.Type
-		}, conditions...),
-		conditions,
-		append(conditions, condition),
-	)
+	meta.SetStatusCondition(&conditions, condition)
+	return conditions
+}
+
+// RemoveCondition removes the condition of the type.
+func RemoveCondition(conditionType string, conditions ...metav1.Condition) []metav1.Condition {
+	meta.RemoveStatusCondition(&conditions, conditionType)
+	return conditions
 }
 
 // SetNatzKeyCondition ...
@@ -37,6 +38,11 @@ func SetNatzKeyCondition(obj *natsv1alpha1.NatsKey, condition metav1.Condition)
 	obj.Status.Conditions = SetCondition(condition, obj.Status.Conditions...)
 }
 
+// RemoveNatzKeyCondition ...
+func RemoveNatzKeyCondition(obj *natsv1alpha1.NatsKey, conditionType string) {
+	obj.Status.Conditions = RemoveCondition(conditionType, obj.Status.Conditions...)
+}
+
 // SetNatzOperatorCondition ...
 func SetNatzOperatorCondition(obj *natsv1alpha1.NatsOperator, condition metav1.Condition) {
 	obj.Status.Conditions = SetCondition(condition, obj.Status.Conditions...)
@@ -52,6 +58,11 @@ func SetNatzUserCondition(obj *natsv1alpha1.NatsUser, condition metav1.Condition
 	obj.Status.Conditions = SetCondition(condition, obj.Status.Conditions...)
 }
 
+// RemoveNatzUserCondition ...
+func RemoveNatzUserCondition(obj *natsv1alpha1.NatsUser, conditionType string) {
+	obj.Status.Conditions = RemoveCondition(conditionType, obj.Status.Conditions...)
+}
+
 // SetNatzConfigCondition ...
 func SetNatzConfigCondition(obj *natsv1alpha1.NatsConfig, condition metav1.Condition) {
 	obj.Status.Conditions = SetCondition(condition, obj.Status.Conditions...)
@@ -223,6 +234,76 @@ func NewUserFailedCondition(obj *natsv1alpha1.NatsUser, err error) metav1.Condit
 	}
 }
 
+// NewAccountImportedCondition creates the condition of the resolution of the imports of the account.
+func NewAccountImportedCondition(obj *natsv1alpha1.NatsAccount, err error) metav1.Condition {
+	if err != nil {
+		return metav1.Condition{
+			Type:               natsv1alpha1.ConditionTypeImported,
+			ObservedGeneration: obj.Generation,
+			Status:             metav1.ConditionFalse,
+			LastTransitionTime: metav1.Now(),
+			Message:            err.Error(),
+			Reason:             natsv1alpha1.ConditionReasonUnresolved,
+		}
+	}
+
+	return metav1.Condition{
+		Type:               natsv1alpha1.ConditionTypeImported,
+		ObservedGeneration: obj.Generation,
+		Status:             metav1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Message:            fmt.Sprintf("the imports of the account are resolved: %s", obj.Name),
+		Reason:             natsv1alpha1.ConditionReasonImported,
+	}
+}
+
+// NewUserForbiddenCondition creates the condition of a user whose namespace is not allowed by the account.
+func NewUserForbiddenCondition(obj *natsv1alpha1.NatsUser, err error) metav1.Condition {
+	return metav1.Condition{
+		Type:               natsv1alpha1.ConditionTypeForbidden,
+		ObservedGeneration: obj.Generation,
+		Status:             metav1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Message:            err.Error(),
+		Reason:             natsv1alpha1.ConditionReasonForbidden,
+	}
+}
+
+// NewUserTrustedCondition creates the condition of the trust of the account in the signer of the user.
+func NewUserTrustedCondition(obj *natsv1alpha1.NatsUser, err error) metav1.Condition {
+	if err != nil {
+		return metav1.Condition{
+			Type:               natsv1alpha1.ConditionTypeTrusted,
+			ObservedGeneration: obj.Generation,
+			Status:             metav1.ConditionFalse,
+			LastTransitionTime: metav1.Now(),
+			Message:            err.Error(),
+			Reason:             natsv1alpha1.ConditionReasonUntrusted,
+		}
+	}
+
+	return metav1.Condition{
+		Type:               natsv1alpha1.ConditionTypeTrusted,
+		ObservedGeneration: obj.Generation,
+		Status:             metav1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Message:            fmt.Sprintf("the signer is trusted by the account: %s", obj.Spec.AccountRef.Name),
+		Reason:             natsv1alpha1.ConditionReasonTrusted,
+	}
+}
+
+// NewKeyRotationBlockedCondition creates the condition of a rotation that is blocked, as the key is the identity of an operator or account.
+func NewKeyRotationBlockedCondition(obj *natsv1alpha1.NatsKey, err error) metav1.Condition {
+	return metav1.Condition{
+		Type:               natsv1alpha1.ConditionTypeRotationBlocked,
+		ObservedGeneration: obj.Generation,
+		Status:             metav1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Message:            err.Error(),
+		Reason:             natsv1alpha1.ConditionReasonIdentityKey,
+	}
+}
+
 // NewKeyFailedCondition creates the provisioning started condition in cluster conditions.
 func NewKeyFailedCondition(obj *natsv1alpha1.NatsKey, err error) metav1.Condition {
 	return metav1.Condition{
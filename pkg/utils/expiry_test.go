@@ -0,0 +1,89 @@
+package utils_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/katallaxie/natz-operator/pkg/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseExpiry(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		in       string
+		expected int64
+	}{
+		{name: "empty", in: "", expected: 0},
+		{name: "zero", in: "0", expected: 0},
+		{name: "zero relative", in: "0d", expected: 0},
+		{name: "date", in: "2030-01-02", expected: time.Date(2030, 1, 2, 0, 0, 0, 0, time.UTC).Unix()},
+		{name: "date and time", in: "2030-01-02 15:04:05 UTC", expected: time.Date(2030, 1, 2, 15, 4, 5, 0, time.UTC).Unix()},
+		{name: "past date", in: "2020-01-02", expected: time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC).Unix()},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			expiry, err := utils.ParseExpiry(tc.in)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, expiry)
+		})
+	}
+}
+
+func TestParseExpiryRelative(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+
+	tests := []struct {
+		name     string
+		in       string
+		expected time.Time
+	}{
+		{name: "minutes", in: "30m", expected: now.Add(30 * time.Minute)},
+		{name: "hours", in: "2h", expected: now.Add(2 * time.Hour)},
+		{name: "days", in: "3d", expected: now.AddDate(0, 0, 3)},
+		{name: "weeks", in: "1w", expected: now.AddDate(0, 0, 7)},
+		{name: "months", in: "6M", expected: now.AddDate(0, 6, 0)},
+		{name: "years", in: "1y", expected: now.AddDate(1, 0, 0)},
+		{name: "past", in: "-1d", expected: now.AddDate(0, 0, -1)},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			expiry, err := utils.ParseExpiry(tc.in)
+			require.NoError(t, err)
+			require.InDelta(t, tc.expected.Unix(), expiry, 5)
+		})
+	}
+}
+
+func TestParseExpiryErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		in   string
+	}{
+		{name: "fraction", in: "1.5h"},
+		{name: "unknown interval", in: "10x"},
+		{name: "invalid date", in: "2030-13-45"},
+		{name: "text", in: "tomorrow"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := utils.ParseExpiry(tc.in)
+			require.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/nats-io/jwt/v2"
+)
+
+var templateRe = regexp.MustCompile(`{{\s*(name|subject|tag)\(\s*([\w-]*)\s*\)\s*}}`)
+
+// ExpandTemplate expands the templates of a subject with the name, the public key and the tags of a user.
+// The templates {{name()}}, {{subject()}} and {{tag(name)}} are supported,
+// a tag with multiple values expands to a subject for each value.
+func ExpandTemplate(subject, name, publicKey string, tags jwt.TagList) ([]string, error) {
+	m := templateRe.FindStringSubmatchIndex(subject)
+	if m == nil {
+		return []string{subject}, nil
+	}
+
+	var values []string
+
+	switch fn := subject[m[2]:m[3]]; fn {
+	case "name":
+		values = []string{name}
+	case "subject":
+		values = []string{publicKey}
+	case "tag":
+		prefix := strings.ToLower(subject[m[4]:m[5]]) + ":"
+		for _, tag := range tags {
+			if v, ok := strings.CutPrefix(tag, prefix); ok && v != "" {
+				values = append(values, v)
+			}
+		}
+	}
+
+	if len(values) == 0 {
+		return nil, fmt.Errorf("template %q of subject %q can not be resolved", subject[m[0]:m[1]], subject)
+	}
+
+	rest, err := ExpandTemplate(subject[m[1]:], name, publicKey, tags)
+	if err != nil {
+		return nil, err
+	}
+
+	subjects := make([]string, 0, len(values)*len(rest))
+	for _, v := range values {
+		for _, r := range rest {
+			subjects = append(subjects, subject[:m[0]]+v+r)
+		}
+	}
+
+	return subjects, nil
+}
+
+// ExpandTemplates expands the templates of the subjects.
+func ExpandTemplates(subjects jwt.StringList, name, publicKey string, tags jwt.TagList) (jwt.StringList, error) {
+	if len(subjects) == 0 {
+		return subjects, nil
+	}
+
+	expanded := jwt.StringList{}
+	for _, subject := range subjects {
+		s, err := ExpandTemplate(subject, name, publicKey, tags)
+		if err != nil {
+			return nil, err
+		}
+
+		expanded.Add(s...)
+	}
+
+	return expanded, nil
+}
@@ -0,0 +1,84 @@
+package utils_test
+
+import (
+	"testing"
+
+	"github.com/katallaxie/natz-operator/pkg/utils"
+	"github.com/nats-io/jwt/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandTemplate(t *testing.T) {
+	t.Parallel()
+
+	tags := jwt.TagList{"team:a", "team:b", "region:eu", "empty:"}
+
+	tests := []struct {
+		name     string
+		in       string
+		expected []string
+	}{
+		{name: "no template", in: "foo.>", expected: []string{"foo.>"}},
+		{name: "name", in: "users.{{name()}}.>", expected: []string{"users.alice.>"}},
+		{name: "subject", in: "users.{{ subject() }}", expected: []string{"users.UABC"}},
+		{name: "tag", in: "region.{{tag(region)}}", expected: []string{"region.eu"}},
+		{name: "tag case", in: "region.{{tag(Region)}}", expected: []string{"region.eu"}},
+		{name: "multi value tag", in: "teams.{{tag(team)}}.>", expected: []string{"teams.a.>", "teams.b.>"}},
+		{
+			name:     "multiple templates",
+			in:       "{{tag(region)}}.{{tag(team)}}.{{name()}}",
+			expected: []string{"eu.a.alice", "eu.b.alice"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			subjects, err := utils.ExpandTemplate(tc.in, "alice", "UABC", tags)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, subjects)
+		})
+	}
+}
+
+func TestExpandTemplateErrors(t *testing.T) {
+	t.Parallel()
+
+	tags := jwt.TagList{"team:a", "empty:"}
+
+	tests := []struct {
+		name string
+		in   string
+	}{
+		{name: "missing tag", in: "region.{{tag(region)}}"},
+		{name: "empty tag", in: "empty.{{tag(empty)}}"},
+		{name: "missing tag after resolved", in: "{{tag(team)}}.{{tag(region)}}"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := utils.ExpandTemplate(tc.in, "alice", "UABC", tags)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestExpandTemplates(t *testing.T) {
+	t.Parallel()
+
+	tags := jwt.TagList{"team:a", "team:b"}
+
+	subjects, err := utils.ExpandTemplates(jwt.StringList{"teams.{{tag(team)}}", "teams.a", "_INBOX.>"}, "alice", "UABC", tags)
+	require.NoError(t, err)
+	require.Equal(t, jwt.StringList{"teams.a", "teams.b", "_INBOX.>"}, subjects)
+
+	_, err = utils.ExpandTemplates(jwt.StringList{"teams.{{tag(region)}}"}, "alice", "UABC", tags)
+	require.Error(t, err)
+
+	subjects, err = utils.ExpandTemplates(nil, "alice", "UABC", tags)
+	require.NoError(t, err)
+	require.Empty(t, subjects)
+}
@@ -0,0 +1,10 @@
+//go:build generate
+// +build generate
+
+//go:generate go run -tags generate sigs.k8s.io/controller-tools/cmd/controller-gen@v0.16.3 webhook output:webhook:artifacts:config=../manifests/webhook paths="./..."
+
+package webhooks
+
+import (
+	_ "sigs.k8s.io/controller-tools/cmd/controller-gen" //nolint:typecheck
+)
@@ -0,0 +1,243 @@
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	natsv1alpha1 "github.com/katallaxie/natz-operator/api/v1alpha1"
+
+	"github.com/katallaxie/pkg/utilx"
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilvalidation "k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// NatsAccountValidator validates NatsAccount resources.
+type NatsAccountValidator struct {
+	client.Reader
+}
+
+// NewNatsAccountValidator ...
+func NewNatsAccountValidator(mgr ctrl.Manager) *NatsAccountValidator {
+	return &NatsAccountValidator{
+		Reader: mgr.GetClient(),
+	}
+}
+
+//+kubebuilder:webhook:path=/validate-natz-katallaxie-dev-v1alpha1-natsaccount,mutating=false,failurePolicy=fail,sideEffects=None,groups=natz.katallaxie.dev,resources=natsaccounts,verbs=create;update,versions=v1alpha1,name=vnatsaccount.natz.katallaxie.dev,admissionReviewVersions=v1
+
+// ValidateCreate ...
+func (v *NatsAccountValidator) ValidateCreate(ctx context.Context, obj *natsv1alpha1.NatsAccount) (admission.Warnings, error) {
+	return v.validate(ctx, obj)
+}
+
+// ValidateUpdate ...
+func (v *NatsAccountValidator) ValidateUpdate(ctx context.Context, _, newObj *natsv1alpha1.NatsAccount) (admission.Warnings, error) {
+	if isDeleting(newObj) {
+		return nil, nil
+	}
+
+	return v.validate(ctx, newObj)
+}
+
+// ValidateDelete ...
+func (v *NatsAccountValidator) ValidateDelete(_ context.Context, _ *natsv1alpha1.NatsAccount) (admission.Warnings, error) {
+	return nil, nil
+}
+
+//nolint:gocyclo
+func (v *NatsAccountValidator) validate(ctx context.Context, obj *natsv1alpha1.NatsAccount) (admission.Warnings, error) {
+	val := newValidation(v)
+	spec := field.NewPath("spec")
+
+	skName := client.ObjectKey{
+		Namespace: utilx.Or(obj.Spec.SignerKeyRef.Namespace, obj.Namespace),
+		Name:      obj.Spec.SignerKeyRef.Name,
+	}
+	if err := val.keyRef(ctx, spec.Child("signerKeyRef"), skName, natsv1alpha1.KeyTypeOperator); err != nil {
+		return nil, err
+	}
+
+	pkName := client.ObjectKey{Namespace: obj.Namespace, Name: obj.Spec.PrivateKey.Name}
+	if err := val.keyRef(ctx, spec.Child("privateKey"), pkName, natsv1alpha1.KeyTypeAccount); err != nil {
+		return nil, err
+	}
+
+	if err := val.identityKeyRef(ctx, spec.Child("privateKey"), pkName); err != nil {
+		return nil, err
+	}
+
+	for i, key := range obj.Spec.SigningKeys {
+		skName := client.ObjectKey{Namespace: obj.Namespace, Name: key.Name}
+		if err := val.keyRef(ctx, spec.Child("signingKeys").Index(i), skName, natsv1alpha1.KeyTypeAccount); err != nil {
+			return nil, err
+		}
+	}
+
+	for i, key := range obj.Spec.ScopedSigningKeys {
+		path := spec.Child("scopedSigningKeys").Index(i)
+
+		skName := client.ObjectKey{Namespace: obj.Namespace, Name: key.Name}
+		if err := val.keyRef(ctx, path, skName, natsv1alpha1.KeyTypeAccount); err != nil {
+			return nil, err
+		}
+
+		if slices.ContainsFunc(obj.Spec.SigningKeys, func(ref natsv1alpha1.NatsKeyReference) bool { return ref.Name == key.Name }) {
+			val.errs = append(val.errs, field.Duplicate(path.Child("name"), key.Name))
+		}
+
+		if key.Role == "" {
+			val.errs = append(val.errs, field.Required(path.Child("role"), "a scoped signing key must have a role"))
+		}
+
+		val.permission(path.Child("template", "permissions", "pub"), key.Template.Permissions.Pub, false)
+		val.permission(path.Child("template", "permissions", "sub"), key.Template.Permissions.Sub, true)
+	}
+
+	for i, ns := range obj.Spec.AllowUserNamespaces {
+		if _, err := path.Match(ns, ""); err != nil {
+			val.errs = append(val.errs, field.Invalid(spec.Child("allowedUserNamespaces").Index(i), ns, err.Error()))
+			continue
+		}
+
+		if strings.ContainsAny(ns, "*?[") {
+			continue
+		}
+
+		for _, msg := range utilvalidation.IsDNS1123Label(ns) {
+			val.errs = append(val.errs, field.Invalid(spec.Child("allowedUserNamespaces").Index(i), ns, msg))
+		}
+	}
+
+	if obj.Spec.AllowUserNamespaceSelector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(obj.Spec.AllowUserNamespaceSelector); err != nil {
+			val.errs = append(val.errs, field.Invalid(spec.Child("allowedUserNamespaceSelector"), obj.Spec.AllowUserNamespaceSelector, err.Error()))
+		}
+	}
+
+	exports := obj.Spec.ToJWTAccount().Exports
+	for i, export := range exports {
+		vr := jwt.CreateValidationResults()
+		export.Validate(vr)
+		val.results(spec.Child("exports").Index(i), export.Subject, vr)
+	}
+
+	for i, imp := range obj.Spec.AccountImports {
+		path := spec.Child("accountImports").Index(i)
+
+		exporter := &natsv1alpha1.NatsAccount{}
+		exporterName := client.ObjectKey{Namespace: utilx.Or(imp.AccountRef.Namespace, obj.Namespace), Name: imp.AccountRef.Name}
+
+		ok, err := val.ref(ctx, path.Child("accountRef"), exporterName, exporter)
+		if err != nil {
+			return nil, err
+		}
+
+		if imp.Export == "" {
+			val.errs = append(val.errs, field.Required(path.Child("export"), "an import requires the name of an export"))
+			continue
+		}
+
+		if !ok {
+			continue
+		}
+
+		export, found := exporter.Spec.FindExport(imp.Export)
+		if !found {
+			val.warnings = append(val.warnings, fmt.Sprintf("%s: account %s has no export %s", path.Child("export"), exporterName, imp.Export))
+			continue
+		}
+
+		if imp.LocalSubject != "" {
+			vr := jwt.CreateValidationResults()
+			imp.LocalSubject.Validate(export.Subject, vr)
+			val.results(path.Child("local_subject"), imp.LocalSubject, vr)
+		}
+	}
+
+	for i, imp := range obj.Spec.Imports {
+		path := spec.Child("imports").Index(i)
+
+		if imp == nil {
+			val.errs = append(val.errs, field.Required(path, "an import must not be empty"))
+			continue
+		}
+
+		if !imp.IsService() && !imp.IsStream() {
+			val.errs = append(val.errs, field.NotSupported(path.Child("type"), imp.Type, []string{jwt.Stream.String(), jwt.Service.String()}))
+		}
+
+		if !nkeys.IsValidPublicAccountKey(imp.Account) {
+			val.errs = append(val.errs, field.Invalid(path.Child("account"), imp.Account, "must be the public key of an account"))
+		}
+
+		val.subject(path.Child("subject"), imp.Subject)
+
+		if imp.LocalSubject != "" {
+			vr := jwt.CreateValidationResults()
+			imp.LocalSubject.Validate(imp.Subject, vr)
+			val.results(path.Child("local_subject"), imp.LocalSubject, vr)
+		}
+	}
+
+	authorization := obj.Spec.Authorization
+	for i, user := range authorization.AuthUsers {
+		if !nkeys.IsValidPublicUserKey(user) {
+			val.errs = append(val.errs, field.Invalid(spec.Child("authorization", "auth_users").Index(i), user, "must be the public key of a user"))
+		}
+	}
+
+	for i, account := range authorization.AllowedAccounts {
+		if account != jwt.AnyAccount && !nkeys.IsValidPublicAccountKey(account) {
+			val.errs = append(val.errs, field.Invalid(spec.Child("authorization", "allowed_accounts").Index(i), account, "must be the public key of an account or *"))
+		}
+	}
+
+	if authorization.XKey != "" && !nkeys.IsValidPublicCurveKey(authorization.XKey) {
+		val.errs = append(val.errs, field.Invalid(spec.Child("authorization", "xkey"), authorization.XKey, "must be a public curve key"))
+	}
+
+	for i, key := range authorization.AuthUserKeyRefs {
+		keyName := client.ObjectKey{Namespace: obj.Namespace, Name: key.Name}
+		if err := val.keyRef(ctx, spec.Child("authorization", "authUserKeyRefs").Index(i), keyName, natsv1alpha1.KeyTypeUser); err != nil {
+			return nil, err
+		}
+	}
+
+	if ref := authorization.XKeyRef; ref != nil {
+		if authorization.XKey != "" {
+			val.errs = append(val.errs, field.Forbidden(spec.Child("authorization", "xkeyRef"), "a xkeyRef can not be used together with a xkey"))
+		}
+
+		keyName := client.ObjectKey{Namespace: obj.Namespace, Name: ref.Name}
+		if err := val.keyRef(ctx, spec.Child("authorization", "xkeyRef"), keyName, natsv1alpha1.KeyTypeCurve); err != nil {
+			return nil, err
+		}
+	}
+
+	hasAuthUsers := len(authorization.AuthUsers) > 0 || len(authorization.AuthUserKeyRefs) > 0
+	hasXKey := authorization.XKey != "" || authorization.XKeyRef != nil
+
+	if !hasAuthUsers && (len(authorization.AllowedAccounts) > 0 || hasXKey) {
+		val.errs = append(val.errs, field.Required(spec.Child("authorization", "auth_users"), "the auth callout requires the users of the auth callout service"))
+	}
+
+	val.publish(spec.Child("publish"), obj.Spec.Publish)
+
+	return val.result("NatsAccount", obj)
+}
+
+// SetupWebhookWithManager sets up the webhook with the Manager.
+func (v *NatsAccountValidator) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr, &natsv1alpha1.NatsAccount{}).
+		WithValidator(v).
+		Complete()
+}
@@ -0,0 +1,106 @@
+package webhooks
+
+import (
+	"context"
+
+	natsv1alpha1 "github.com/katallaxie/natz-operator/api/v1alpha1"
+	"github.com/katallaxie/natz-operator/pkg/utils"
+
+	"github.com/katallaxie/pkg/utilx"
+	"github.com/nats-io/jwt/v2"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// NatsActivationValidator validates NatsActivation resources.
+type NatsActivationValidator struct {
+	client.Reader
+}
+
+// NewNatsActivationValidator ...
+func NewNatsActivationValidator(mgr ctrl.Manager) *NatsActivationValidator {
+	return &NatsActivationValidator{
+		Reader: mgr.GetClient(),
+	}
+}
+
+//+kubebuilder:webhook:path=/validate-natz-katallaxie-dev-v1alpha1-natsactivation,mutating=false,failurePolicy=fail,sideEffects=None,groups=natz.katallaxie.dev,resources=natsactivations,verbs=create;update,versions=v1alpha1,name=vnatsactivation.natz.katallaxie.dev,admissionReviewVersions=v1
+
+// ValidateCreate ...
+func (v *NatsActivationValidator) ValidateCreate(ctx context.Context, obj *natsv1alpha1.NatsActivation) (admission.Warnings, error) {
+	return v.validate(ctx, obj)
+}
+
+// ValidateUpdate ...
+func (v *NatsActivationValidator) ValidateUpdate(ctx context.Context, _, newObj *natsv1alpha1.NatsActivation) (admission.Warnings, error) {
+	if isDeleting(newObj) {
+		return nil, nil
+	}
+
+	return v.validate(ctx, newObj)
+}
+
+// ValidateDelete ...
+func (v *NatsActivationValidator) ValidateDelete(_ context.Context, _ *natsv1alpha1.NatsActivation) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (v *NatsActivationValidator) validate(ctx context.Context, obj *natsv1alpha1.NatsActivation) (admission.Warnings, error) {
+	val := newValidation(v)
+	spec := field.NewPath("spec")
+
+	skName := client.ObjectKey{Namespace: obj.Namespace, Name: obj.Spec.SignerKeyRef.Name}
+	if err := val.keyRef(ctx, spec.Child("signerKeyRef"), skName, natsv1alpha1.KeyTypeAccount); err != nil {
+		return nil, err
+	}
+
+	accountName := client.ObjectKey{Namespace: obj.Namespace, Name: obj.Spec.AccountRef.Name}
+	if _, err := val.ref(ctx, spec.Child("accountRef"), accountName, &natsv1alpha1.NatsAccount{}); err != nil {
+		return nil, err
+	}
+
+	targetName := client.ObjectKey{
+		Namespace: utilx.Or(obj.Spec.TargetAccountRef.Namespace, obj.Namespace),
+		Name:      obj.Spec.TargetAccountRef.Name,
+	}
+	if _, err := val.ref(ctx, spec.Child("targetAccountRef"), targetName, &natsv1alpha1.NatsAccount{}); err != nil {
+		return nil, err
+	}
+
+	val.subject(spec.Child("subject"), jwt.Subject(obj.Spec.Subject))
+
+	if obj.Spec.ExportType != natsv1alpha1.Stream && obj.Spec.ExportType != natsv1alpha1.Service {
+		val.errs = append(val.errs, field.Invalid(spec.Child("exportType"), obj.Spec.ExportType, "must be a stream or a service"))
+	}
+
+	if !obj.Spec.Expiry.IsZero() && !obj.Spec.Start.IsZero() && !obj.Spec.Expiry.After(obj.Spec.Start.Time) {
+		val.errs = append(val.errs, field.Invalid(spec.Child("expiry"), obj.Spec.Expiry, "must be after the start"))
+	}
+
+	if obj.Spec.TTL != "" {
+		if _, err := utils.ParseExpiry(obj.Spec.TTL); err != nil {
+			val.errs = append(val.errs, field.Invalid(spec.Child("ttl"), obj.Spec.TTL, err.Error()))
+		}
+
+		if !obj.Spec.Expiry.IsZero() {
+			val.errs = append(val.errs, field.Forbidden(spec.Child("ttl"), "a ttl can not be used together with an expiry"))
+		}
+	}
+
+	if obj.Spec.RenewBefore.Duration < 0 {
+		val.errs = append(val.errs, field.Invalid(spec.Child("renewBefore"), obj.Spec.RenewBefore, "must not be negative"))
+	}
+
+	val.publish(spec.Child("publish"), obj.Spec.Publish)
+
+	return val.result("NatsActivation", obj)
+}
+
+// SetupWebhookWithManager sets up the webhook with the Manager.
+func (v *NatsActivationValidator) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr, &natsv1alpha1.NatsActivation{}).
+		WithValidator(v).
+		Complete()
+}
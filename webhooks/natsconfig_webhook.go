@@ -0,0 +1,106 @@
+package webhooks
+
+import (
+	"context"
+	"net/url"
+
+	natsv1alpha1 "github.com/katallaxie/natz-operator/api/v1alpha1"
+
+	"github.com/katallaxie/pkg/utilx"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// NatsConfigValidator validates NatsConfig resources.
+type NatsConfigValidator struct {
+	client.Reader
+}
+
+// NewNatsConfigValidator ...
+func NewNatsConfigValidator(mgr ctrl.Manager) *NatsConfigValidator {
+	return &NatsConfigValidator{
+		Reader: mgr.GetClient(),
+	}
+}
+
+//+kubebuilder:webhook:path=/validate-natz-katallaxie-dev-v1alpha1-natsconfig,mutating=false,failurePolicy=fail,sideEffects=None,groups=natz.katallaxie.dev,resources=natsconfigs,verbs=create;update,versions=v1alpha1,name=vnatsconfig.natz.katallaxie.dev,admissionReviewVersions=v1
+
+// ValidateCreate ...
+func (v *NatsConfigValidator) ValidateCreate(ctx context.Context, obj *natsv1alpha1.NatsConfig) (admission.Warnings, error) {
+	return v.validate(ctx, obj)
+}
+
+// ValidateUpdate ...
+func (v *NatsConfigValidator) ValidateUpdate(ctx context.Context, _, newObj *natsv1alpha1.NatsConfig) (admission.Warnings, error) {
+	if isDeleting(newObj) {
+		return nil, nil
+	}
+
+	return v.validate(ctx, newObj)
+}
+
+// ValidateDelete ...
+func (v *NatsConfigValidator) ValidateDelete(_ context.Context, _ *natsv1alpha1.NatsConfig) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (v *NatsConfigValidator) validate(ctx context.Context, obj *natsv1alpha1.NatsConfig) (admission.Warnings, error) {
+	val := newValidation(v)
+	spec := field.NewPath("spec")
+
+	operatorName := client.ObjectKey{Namespace: obj.Namespace, Name: obj.Spec.OperatorRef.Name}
+	if _, err := val.ref(ctx, spec.Child("operatorRef"), operatorName, &natsv1alpha1.NatsOperator{}); err != nil {
+		return nil, err
+	}
+
+	systemAccountName := client.ObjectKey{Namespace: obj.Namespace, Name: obj.Spec.SystemAccountRef.Name}
+	if _, err := val.ref(ctx, spec.Child("systemAccountRef"), systemAccountName, &natsv1alpha1.NatsAccount{}); err != nil {
+		return nil, err
+	}
+
+	for i, ref := range obj.Spec.Gateways {
+		gatewayName := client.ObjectKey{Namespace: utilx.Or(ref.Namespace, obj.Namespace), Name: ref.Name}
+		if _, err := val.ref(ctx, spec.Child("gateways").Index(i), gatewayName, &natsv1alpha1.NatsGateway{}); err != nil {
+			return nil, err
+		}
+	}
+
+	for i, u := range obj.Spec.ClientURLs {
+		if parsed, err := url.Parse(u); err != nil || parsed.Scheme == "" || parsed.Host == "" {
+			val.errs = append(val.errs, field.Invalid(spec.Child("clientURLs").Index(i), u, "must be a URL with a scheme and host, e.g. nats://nats.default.svc:4222"))
+		}
+	}
+
+	return val.result("NatsConfig", obj)
+}
+
+// SetupWebhookWithManager sets up the webhook with the Manager.
+func (v *NatsConfigValidator) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr, &natsv1alpha1.NatsConfig{}).
+		WithValidator(v).
+		WithDefaulter(NewNatsConfigDefaulter()).
+		Complete()
+}
+
+// NatsConfigDefaulter defaults NatsConfig resources.
+type NatsConfigDefaulter struct{}
+
+// NewNatsConfigDefaulter ...
+func NewNatsConfigDefaulter() *NatsConfigDefaulter {
+	return &NatsConfigDefaulter{}
+}
+
+//+kubebuilder:webhook:path=/mutate-natz-katallaxie-dev-v1alpha1-natsconfig,mutating=true,failurePolicy=fail,sideEffects=None,groups=natz.katallaxie.dev,resources=natsconfigs,verbs=create;update,versions=v1alpha1,name=mnatsconfig.natz.katallaxie.dev,admissionReviewVersions=v1
+
+// Default applies the defaults of the configuration.
+func (d *NatsConfigDefaulter) Default(_ context.Context, obj *natsv1alpha1.NatsConfig) error {
+	if isDeleting(obj) {
+		return nil
+	}
+
+	obj.Spec.Default()
+
+	return nil
+}
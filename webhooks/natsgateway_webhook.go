@@ -0,0 +1,77 @@
+package webhooks
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	natsv1alpha1 "github.com/katallaxie/natz-operator/api/v1alpha1"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// NatsGatewayValidator validates NatsGateway resources.
+type NatsGatewayValidator struct {
+	client.Reader
+}
+
+// NewNatsGatewayValidator ...
+func NewNatsGatewayValidator(mgr ctrl.Manager) *NatsGatewayValidator {
+	return &NatsGatewayValidator{
+		Reader: mgr.GetClient(),
+	}
+}
+
+//+kubebuilder:webhook:path=/validate-natz-katallaxie-dev-v1alpha1-natsgateway,mutating=false,failurePolicy=fail,sideEffects=None,groups=natz.katallaxie.dev,resources=natsgateways,verbs=create;update,versions=v1alpha1,name=vnatsgateway.natz.katallaxie.dev,admissionReviewVersions=v1
+
+// ValidateCreate ...
+func (v *NatsGatewayValidator) ValidateCreate(ctx context.Context, obj *natsv1alpha1.NatsGateway) (admission.Warnings, error) {
+	return v.validate(ctx, obj)
+}
+
+// ValidateUpdate ...
+func (v *NatsGatewayValidator) ValidateUpdate(ctx context.Context, _, newObj *natsv1alpha1.NatsGateway) (admission.Warnings, error) {
+	if isDeleting(newObj) {
+		return nil, nil
+	}
+
+	return v.validate(ctx, newObj)
+}
+
+// ValidateDelete ...
+func (v *NatsGatewayValidator) ValidateDelete(_ context.Context, _ *natsv1alpha1.NatsGateway) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (v *NatsGatewayValidator) validate(ctx context.Context, obj *natsv1alpha1.NatsGateway) (admission.Warnings, error) {
+	val := newValidation(v)
+	spec := field.NewPath("spec")
+
+	if strings.ContainsAny(obj.Spec.Name, " \t\r\n") {
+		val.errs = append(val.errs, field.Invalid(spec.Child("name"), obj.Spec.Name, "must not contain whitespace"))
+	}
+
+	if _, err := url.ParseRequestURI(obj.Spec.URL); err != nil {
+		val.errs = append(val.errs, field.Invalid(spec.Child("url"), obj.Spec.URL, err.Error()))
+	}
+
+	if err := val.secretRef(ctx, spec.Child("username"), obj.Namespace, obj.Spec.Username); err != nil {
+		return nil, err
+	}
+
+	if err := val.secretRef(ctx, spec.Child("password"), obj.Namespace, obj.Spec.Password); err != nil {
+		return nil, err
+	}
+
+	return val.result("NatsGateway", obj)
+}
+
+// SetupWebhookWithManager sets up the webhook with the Manager.
+func (v *NatsGatewayValidator) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr, &natsv1alpha1.NatsGateway{}).
+		WithValidator(v).
+		Complete()
+}
@@ -0,0 +1,169 @@
+package webhooks
+
+import (
+	"context"
+	"fmt"
+
+	natsv1alpha1 "github.com/katallaxie/natz-operator/api/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// NatsKeyValidator validates NatsKey resources.
+type NatsKeyValidator struct {
+	client.Reader
+}
+
+// NewNatsKeyValidator ...
+func NewNatsKeyValidator(mgr ctrl.Manager) *NatsKeyValidator {
+	return &NatsKeyValidator{
+		Reader: mgr.GetClient(),
+	}
+}
+
+//+kubebuilder:webhook:path=/validate-natz-katallaxie-dev-v1alpha1-natskey,mutating=false,failurePolicy=fail,sideEffects=None,groups=natz.katallaxie.dev,resources=natskeys,verbs=create;update,versions=v1alpha1,name=vnatskey.natz.katallaxie.dev,admissionReviewVersions=v1
+
+// ValidateCreate ...
+func (v *NatsKeyValidator) ValidateCreate(ctx context.Context, obj *natsv1alpha1.NatsKey) (admission.Warnings, error) {
+	val, err := v.validate(ctx, obj)
+	if err != nil {
+		return nil, err
+	}
+
+	return val.result("NatsKey", obj)
+}
+
+// ValidateUpdate ...
+func (v *NatsKeyValidator) ValidateUpdate(ctx context.Context, oldObj, newObj *natsv1alpha1.NatsKey) (admission.Warnings, error) {
+	if isDeleting(newObj) {
+		return nil, nil
+	}
+
+	val, err := v.validate(ctx, newObj)
+	if err != nil {
+		return nil, err
+	}
+
+	if oldObj.Spec.Type != newObj.Spec.Type {
+		val.errs = append(val.errs, field.Forbidden(field.NewPath("spec", "type"), "the type of a key is immutable"))
+	}
+
+	trigger := newObj.Annotations[natsv1alpha1.AnnotationRotateKey]
+	if trigger != "" && trigger != oldObj.Annotations[natsv1alpha1.AnnotationRotateKey] {
+		path := field.NewPath("metadata", "annotations").Key(natsv1alpha1.AnnotationRotateKey)
+		if err := v.validateRotation(ctx, val, path, newObj); err != nil {
+			return nil, err
+		}
+	}
+
+	return val.result("NatsKey", newObj)
+}
+
+// ValidateDelete ...
+func (v *NatsKeyValidator) ValidateDelete(_ context.Context, _ *natsv1alpha1.NatsKey) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (v *NatsKeyValidator) validate(ctx context.Context, obj *natsv1alpha1.NatsKey) (*validation, error) {
+	val := newValidation(v)
+
+	if _, err := obj.Keys(); err != nil {
+		val.errs = append(val.errs, field.NotSupported(field.NewPath("spec", "type"), obj.Spec.Type, natsv1alpha1.KeyTypes))
+	}
+
+	if obj.Spec.Rotation != nil && obj.Spec.Rotation.Interval.Duration < 0 {
+		val.errs = append(val.errs, field.Invalid(field.NewPath("spec", "rotation", "interval"), obj.Spec.Rotation.Interval, "must not be negative"))
+	}
+
+	if obj.Spec.Rotation != nil && obj.Spec.Rotation.GracePeriod.Duration < 0 {
+		val.errs = append(val.errs, field.Invalid(field.NewPath("spec", "rotation", "gracePeriod"), obj.Spec.Rotation.GracePeriod, "must not be negative"))
+	}
+
+	if obj.HasRotationPolicy() {
+		if err := v.validateRotation(ctx, val, field.NewPath("spec", "rotation", "interval"), obj); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := v.validateSeed(ctx, val, field.NewPath("spec", "seed"), obj); err != nil {
+		return nil, err
+	}
+
+	return val, nil
+}
+
+// validateRotation checks that the key is not the private key of an operator or account.
+// Their public key is their identity, only signing keys can be rotated.
+func (v *NatsKeyValidator) validateRotation(ctx context.Context, val *validation, path *field.Path, obj *natsv1alpha1.NatsKey) error {
+	operators := &natsv1alpha1.NatsOperatorList{}
+	if err := v.List(ctx, operators, client.InNamespace(obj.Namespace)); err != nil {
+		return err
+	}
+
+	for _, operator := range operators.Items {
+		if operator.Spec.PrivateKey.Name == obj.Name {
+			val.errs = append(val.errs, field.Forbidden(path, fmt.Sprintf("the key is the private key of operator %s, only signing keys can be rotated", operator.Name)))
+		}
+	}
+
+	accounts := &natsv1alpha1.NatsAccountList{}
+	if err := v.List(ctx, accounts, client.InNamespace(obj.Namespace)); err != nil {
+		return err
+	}
+
+	for _, account := range accounts.Items {
+		if account.Spec.PrivateKey.Name == obj.Name {
+			val.errs = append(val.errs, field.Forbidden(path, fmt.Sprintf("the key is the private key of account %s, only signing keys can be rotated", account.Name)))
+		}
+	}
+
+	return nil
+}
+
+// validateSeed checks that an imported seed is of the type of the key.
+// The seed is never part of an error.
+func (v *NatsKeyValidator) validateSeed(ctx context.Context, val *validation, path *field.Path, obj *natsv1alpha1.NatsKey) error {
+	if obj.Spec.Seed.SecretKeyRef == nil {
+		return nil
+	}
+
+	if obj.Spec.Rotation != nil {
+		val.errs = append(val.errs, field.Forbidden(field.NewPath("spec", "rotation"), "an imported key is rotated by updating its seed"))
+	}
+
+	ref := obj.Spec.Seed.SecretKeyRef
+	if ref.Key == "" {
+		val.errs = append(val.errs, field.Required(path.Child("secretKeyRef", "key"), "the key of the seed is required"))
+	}
+
+	secret := &corev1.Secret{}
+	secretName := client.ObjectKey{Namespace: obj.Namespace, Name: ref.Name}
+
+	ok, err := val.ref(ctx, path.Child("secretKeyRef"), secretName, secret)
+	if err != nil || !ok || ref.Key == "" {
+		return err
+	}
+
+	seed, found := secret.Data[ref.Key]
+	if !found {
+		val.errs = append(val.errs, field.Invalid(path.Child("secretKeyRef", "key"), ref.Key, fmt.Sprintf("not found in secret %s", secretName)))
+		return nil
+	}
+
+	if _, err := obj.ImportKeys(seed); err != nil {
+		val.errs = append(val.errs, field.Invalid(path.Child("secretKeyRef"), secretName.String(), err.Error()))
+	}
+
+	return nil
+}
+
+// SetupWebhookWithManager sets up the webhook with the Manager.
+func (v *NatsKeyValidator) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr, &natsv1alpha1.NatsKey{}).
+		WithValidator(v).
+		Complete()
+}
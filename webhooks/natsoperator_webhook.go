@@ -0,0 +1,102 @@
+package webhooks
+
+import (
+	"context"
+	"net/url"
+
+	natsv1alpha1 "github.com/katallaxie/natz-operator/api/v1alpha1"
+
+	"github.com/katallaxie/pkg/utilx"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// NatsOperatorValidator validates NatsOperator resources.
+type NatsOperatorValidator struct {
+	client.Reader
+}
+
+// NewNatsOperatorValidator ...
+func NewNatsOperatorValidator(mgr ctrl.Manager) *NatsOperatorValidator {
+	return &NatsOperatorValidator{
+		Reader: mgr.GetClient(),
+	}
+}
+
+//+kubebuilder:webhook:path=/validate-natz-katallaxie-dev-v1alpha1-natsoperator,mutating=false,failurePolicy=fail,sideEffects=None,groups=natz.katallaxie.dev,resources=natsoperators,verbs=create;update,versions=v1alpha1,name=vnatsoperator.natz.katallaxie.dev,admissionReviewVersions=v1
+
+// ValidateCreate ...
+func (v *NatsOperatorValidator) ValidateCreate(ctx context.Context, obj *natsv1alpha1.NatsOperator) (admission.Warnings, error) {
+	return v.validate(ctx, obj)
+}
+
+// ValidateUpdate ...
+func (v *NatsOperatorValidator) ValidateUpdate(ctx context.Context, _, newObj *natsv1alpha1.NatsOperator) (admission.Warnings, error) {
+	if isDeleting(newObj) {
+		return nil, nil
+	}
+
+	return v.validate(ctx, newObj)
+}
+
+// ValidateDelete ...
+func (v *NatsOperatorValidator) ValidateDelete(_ context.Context, _ *natsv1alpha1.NatsOperator) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (v *NatsOperatorValidator) validate(ctx context.Context, obj *natsv1alpha1.NatsOperator) (admission.Warnings, error) {
+	val := newValidation(v)
+	spec := field.NewPath("spec")
+
+	pkName := client.ObjectKey{Namespace: obj.Namespace, Name: obj.Spec.PrivateKey.Name}
+	if err := val.keyRef(ctx, spec.Child("privateKey"), pkName, natsv1alpha1.KeyTypeOperator); err != nil {
+		return nil, err
+	}
+
+	if err := val.identityKeyRef(ctx, spec.Child("privateKey"), pkName); err != nil {
+		return nil, err
+	}
+
+	for i, key := range obj.Spec.SigningKeys {
+		skName := client.ObjectKey{Namespace: obj.Namespace, Name: key.Name}
+		if err := val.keyRef(ctx, spec.Child("signingKeys").Index(i), skName, natsv1alpha1.KeyTypeOperator); err != nil {
+			return nil, err
+		}
+	}
+
+	if obj.Spec.EnableSystemAccount {
+		accountName := client.ObjectKey{
+			Namespace: utilx.Or(obj.Spec.SystemAccountRef.Namespace, obj.Namespace),
+			Name:      obj.Spec.SystemAccountRef.Name,
+		}
+
+		if _, err := val.ref(ctx, spec.Child("systemAccountRef"), accountName, &natsv1alpha1.NatsAccount{}); err != nil {
+			return nil, err
+		}
+	}
+
+	if obj.Spec.AccountServerURL != "" {
+		if _, err := url.ParseRequestURI(obj.Spec.AccountServerURL); err != nil {
+			val.errs = append(val.errs, field.Invalid(spec.Child("accountServerURL"), obj.Spec.AccountServerURL, err.Error()))
+		}
+	}
+
+	for i, u := range obj.Spec.OperatorServiceURLs {
+		if _, err := url.ParseRequestURI(u); err != nil {
+			val.errs = append(val.errs, field.Invalid(spec.Child("operatorServiceURLs").Index(i), u, err.Error()))
+		}
+	}
+
+	val.publish(spec.Child("publish"), obj.Spec.Publish)
+
+	return val.result("NatsOperator", obj)
+}
+
+// SetupWebhookWithManager sets up the webhook with the Manager.
+func (v *NatsOperatorValidator) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr, &natsv1alpha1.NatsOperator{}).
+		WithValidator(v).
+		Complete()
+}
@@ -0,0 +1,206 @@
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+
+	natsv1alpha1 "github.com/katallaxie/natz-operator/api/v1alpha1"
+	"github.com/katallaxie/natz-operator/pkg/utils"
+
+	"github.com/katallaxie/pkg/utilx"
+	corev1 "k8s.io/api/core/v1"
+	utilvalidation "k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// NatsUserValidator validates NatsUser resources.
+type NatsUserValidator struct {
+	client.Reader
+}
+
+// NewNatsUserValidator ...
+func NewNatsUserValidator(mgr ctrl.Manager) *NatsUserValidator {
+	return &NatsUserValidator{
+		Reader: mgr.GetClient(),
+	}
+}
+
+//+kubebuilder:webhook:path=/validate-natz-katallaxie-dev-v1alpha1-natsuser,mutating=false,failurePolicy=fail,sideEffects=None,groups=natz.katallaxie.dev,resources=natsusers,verbs=create;update,versions=v1alpha1,name=vnatsuser.natz.katallaxie.dev,admissionReviewVersions=v1
+
+// ValidateCreate ...
+func (v *NatsUserValidator) ValidateCreate(ctx context.Context, obj *natsv1alpha1.NatsUser) (admission.Warnings, error) {
+	return v.validate(ctx, obj)
+}
+
+// ValidateUpdate ...
+func (v *NatsUserValidator) ValidateUpdate(ctx context.Context, _, newObj *natsv1alpha1.NatsUser) (admission.Warnings, error) {
+	if isDeleting(newObj) {
+		return nil, nil
+	}
+
+	return v.validate(ctx, newObj)
+}
+
+// ValidateDelete ...
+func (v *NatsUserValidator) ValidateDelete(_ context.Context, _ *natsv1alpha1.NatsUser) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (v *NatsUserValidator) validate(ctx context.Context, obj *natsv1alpha1.NatsUser) (admission.Warnings, error) {
+	val := newValidation(v)
+	spec := field.NewPath("spec")
+
+	if obj.Spec.ServiceAccountName == "" {
+		pkName := client.ObjectKey{Namespace: obj.Namespace, Name: obj.Spec.PrivateKey.Name}
+		if err := val.keyRef(ctx, spec.Child("privateKey"), pkName, natsv1alpha1.KeyTypeUser); err != nil {
+			return nil, err
+		}
+	}
+
+	if obj.Spec.ServiceAccountName != "" {
+		for _, msg := range utilvalidation.IsDNS1123Subdomain(obj.Spec.ServiceAccountName) {
+			val.errs = append(val.errs, field.Invalid(spec.Child("serviceAccountName"), obj.Spec.ServiceAccountName, msg))
+		}
+
+		if obj.Spec.PrivateKey.Name != "" {
+			val.errs = append(val.errs, field.Forbidden(spec.Child("privateKey"), "every pod of a user bound to a ServiceAccount has its own key"))
+		}
+
+		if expiry, err := utils.ParseExpiry(obj.Spec.Expiry); err == nil && expiry == 0 {
+			val.errs = append(val.errs, field.Required(spec.Child("expiry"), "the JWTs of the pods of a user bound to a ServiceAccount have to expire"))
+		}
+	}
+
+	account := &natsv1alpha1.NatsAccount{}
+	accountName := client.ObjectKey{Namespace: utilx.Or(obj.Spec.AccountRef.Namespace, obj.Namespace), Name: obj.Spec.AccountRef.Name}
+
+	// the signer key is a key of the account, it is in the namespace of the account
+	skName := client.ObjectKey{Namespace: accountName.Namespace, Name: obj.Spec.SignerKeyRef.Name}
+	if ns := obj.Spec.SignerKeyRef.Namespace; ns != "" && ns != accountName.Namespace {
+		val.errs = append(val.errs, field.Invalid(spec.Child("signerKeyRef", "namespace"), ns, fmt.Sprintf("must be the namespace of account %s", accountName)))
+	}
+
+	if obj.Spec.SignerKeyRef.Name != "" {
+		if err := val.keyRef(ctx, spec.Child("signerKeyRef"), skName, natsv1alpha1.KeyTypeAccount); err != nil {
+			return nil, err
+		}
+	}
+
+	ok, err := val.ref(ctx, spec.Child("accountRef"), accountName, account)
+	if err != nil {
+		return nil, err
+	}
+
+	if ok {
+		ns := &corev1.Namespace{}
+		if err := v.Get(ctx, client.ObjectKey{Name: obj.Namespace}, ns); err != nil {
+			return nil, err
+		}
+
+		allowed, err := account.AllowsUserNamespace(ns.Name, ns.Labels)
+		if err != nil {
+			return nil, err
+		}
+
+		if !allowed {
+			val.errs = append(val.errs, field.Forbidden(spec.Child("accountRef"), fmt.Sprintf("account %s does not allow users in namespace %s", accountName, obj.Namespace)))
+		}
+	}
+
+	if ok {
+		v.validateSigner(val, spec.Child("signerKeyRef"), obj, account, skName)
+	}
+
+	if _, err := utils.ParseExpiry(obj.Spec.Expiry); err != nil {
+		val.errs = append(val.errs, field.Invalid(spec.Child("expiry"), obj.Spec.Expiry, err.Error()))
+	}
+
+	if obj.Spec.RenewBefore.Duration < 0 {
+		val.errs = append(val.errs, field.Invalid(spec.Child("renewBefore"), obj.Spec.RenewBefore, "must not be negative"))
+	}
+
+	if err := v.validateCredentials(ctx, val, spec.Child("credentials"), obj); err != nil {
+		return nil, err
+	}
+
+	val.permission(spec.Child("permissions", "pub"), obj.Spec.Permissions.Pub, false)
+	val.permission(spec.Child("permissions", "sub"), obj.Spec.Permissions.Sub, true)
+
+	return val.result("NatsUser", obj)
+}
+
+func (v *NatsUserValidator) validateCredentials(ctx context.Context, val *validation, path *field.Path, obj *natsv1alpha1.NatsUser) error {
+	credentials := obj.Spec.Credentials
+
+	if credentials.MountPath != "" && !filepath.IsAbs(credentials.MountPath) {
+		val.errs = append(val.errs, field.Invalid(path.Child("mountPath"), credentials.MountPath, "must be an absolute path"))
+	}
+
+	// the pods authenticate with their ServiceAccount token, no credentials are written
+	if obj.Spec.ServiceAccountName != "" && (len(credentials.Formats) > 0 || credentials.MountPath != "" || credentials.ConfigRef != nil) {
+		val.errs = append(val.errs, field.Forbidden(path, "no credentials are written for users bound to a ServiceAccount"))
+		return nil
+	}
+
+	if credentials.ConfigRef == nil {
+		if credentials.HasFormat(natsv1alpha1.CredentialsFormatURL) {
+			val.errs = append(val.errs, field.Required(path.Child("configRef"), "the URL format requires a config"))
+		}
+
+		return nil
+	}
+
+	configName := client.ObjectKey{Namespace: utilx.Or(credentials.ConfigRef.Namespace, obj.Namespace), Name: credentials.ConfigRef.Name}
+	_, err := val.ref(ctx, path.Child("configRef"), configName, &natsv1alpha1.NatsConfig{})
+
+	return err
+}
+
+// validateSigner validates that the signer key of the user is a key of the account that can sign the user.
+// Users outside the namespace of the account can only be signed by a scoped signing key.
+func (v *NatsUserValidator) validateSigner(val *validation, path *field.Path, obj *natsv1alpha1.NatsUser, account *natsv1alpha1.NatsAccount, name client.ObjectKey) {
+	keys := accountKeys(account, obj.Namespace)
+
+	if name.Name == "" {
+		if obj.Namespace != account.Namespace && len(keys) == 0 {
+			val.errs = append(val.errs, field.Required(path, fmt.Sprintf("users in namespace %s require a scoped signing key of account %s", obj.Namespace, client.ObjectKeyFromObject(account))))
+		}
+
+		return
+	}
+
+	if !slices.Contains(keys, name) {
+		val.errs = append(val.errs, field.Forbidden(path, fmt.Sprintf("%s is not a key of account %s that can sign users in namespace %s", name, client.ObjectKeyFromObject(account), obj.Namespace)))
+	}
+}
+
+// accountKeys returns the keys that can sign users of the account in the namespace.
+// Users outside the namespace of the account can only be signed by the scoped signing keys.
+func accountKeys(account *natsv1alpha1.NatsAccount, namespace string) []client.ObjectKey {
+	keys := []client.ObjectKey{}
+
+	if namespace == account.Namespace {
+		keys = append(keys, client.ObjectKey{Namespace: account.Namespace, Name: account.Spec.PrivateKey.Name})
+		for _, key := range account.Spec.SigningKeys {
+			keys = append(keys, client.ObjectKey{Namespace: account.Namespace, Name: key.Name})
+		}
+	}
+
+	for _, key := range account.Spec.ScopedSigningKeys {
+		keys = append(keys, client.ObjectKey{Namespace: account.Namespace, Name: key.Name})
+	}
+
+	return keys
+}
+
+// SetupWebhookWithManager sets up the webhook with the Manager.
+func (v *NatsUserValidator) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr, &natsv1alpha1.NatsUser{}).
+		WithValidator(v).
+		Complete()
+}
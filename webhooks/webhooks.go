@@ -0,0 +1,221 @@
+package webhooks
+
+import (
+	"context"
+	"fmt"
+
+	natsv1alpha1 "github.com/katallaxie/natz-operator/api/v1alpha1"
+
+	"github.com/nats-io/jwt/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	utilvalidation "k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// SetupWithManager sets up the admission webhooks of all resources with the Manager.
+func SetupWithManager(mgr ctrl.Manager) error {
+	err := NewNatsKeyValidator(mgr).SetupWebhookWithManager(mgr)
+	if err != nil {
+		return err
+	}
+
+	err = NewNatsOperatorValidator(mgr).SetupWebhookWithManager(mgr)
+	if err != nil {
+		return err
+	}
+
+	err = NewNatsAccountValidator(mgr).SetupWebhookWithManager(mgr)
+	if err != nil {
+		return err
+	}
+
+	err = NewNatsUserValidator(mgr).SetupWebhookWithManager(mgr)
+	if err != nil {
+		return err
+	}
+
+	err = NewNatsActivationValidator(mgr).SetupWebhookWithManager(mgr)
+	if err != nil {
+		return err
+	}
+
+	err = NewNatsGatewayValidator(mgr).SetupWebhookWithManager(mgr)
+	if err != nil {
+		return err
+	}
+
+	err = NewNatsConfigValidator(mgr).SetupWebhookWithManager(mgr)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// validation collects the errors and warnings of a validated resource.
+//
+// Missing references are reported as warnings, so that resources can be applied in any order.
+type validation struct {
+	client.Reader
+	errs     field.ErrorList
+	warnings admission.Warnings
+}
+
+// newValidation returns a new validation that is reading references with the reader.
+func newValidation(r client.Reader) *validation {
+	return &validation{Reader: r}
+}
+
+// ref checks that the referenced object exists and returns false if it could not be found.
+func (v *validation) ref(ctx context.Context, path *field.Path, key client.ObjectKey, obj client.Object) (bool, error) {
+	if key.Name == "" {
+		v.errs = append(v.errs, field.Required(path.Child("name"), "a reference requires a name"))
+		return false, nil
+	}
+
+	err := v.Get(ctx, key, obj)
+	if errors.IsNotFound(err) {
+		v.warnings = append(v.warnings, fmt.Sprintf("%s: %s", path, err))
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// keyRef checks that the referenced key exists and is of one of the key types.
+func (v *validation) keyRef(ctx context.Context, path *field.Path, key client.ObjectKey, types ...natsv1alpha1.KeyType) error {
+	k := &natsv1alpha1.NatsKey{}
+
+	ok, err := v.ref(ctx, path, key, k)
+	if err != nil || !ok {
+		return err
+	}
+
+	for _, t := range types {
+		if k.Spec.Type == t {
+			return nil
+		}
+	}
+
+	v.errs = append(v.errs, field.Invalid(path, key.Name, fmt.Sprintf("key is of type %s, expected %v", k.Spec.Type, types)))
+
+	return nil
+}
+
+// identityKeyRef checks that the private key of an operator or account has no rotation policy.
+// The public key is the identity of an operator or account, a rotation would replace it.
+func (v *validation) identityKeyRef(ctx context.Context, path *field.Path, key client.ObjectKey) error {
+	if key.Name == "" {
+		return nil
+	}
+
+	k := &natsv1alpha1.NatsKey{}
+	if err := v.Get(ctx, key, k); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+
+	if k.HasRotationPolicy() {
+		v.errs = append(v.errs, field.Forbidden(path, fmt.Sprintf("key %s has a rotation policy, only signing keys can be rotated", key.Name)))
+	}
+
+	return nil
+}
+
+// secretRef checks that the secret of the source exists.
+func (v *validation) secretRef(ctx context.Context, path *field.Path, namespace string, src natsv1alpha1.SecretValueFromSource) error {
+	if src.SecretKeyRef == nil {
+		return nil
+	}
+
+	secretName := client.ObjectKey{Namespace: namespace, Name: src.SecretKeyRef.Name}
+	_, err := v.ref(ctx, path.Child("secretKeyRef"), secretName, &corev1.Secret{})
+
+	return err
+}
+
+// subject checks the syntax of a subject.
+func (v *validation) subject(path *field.Path, subject jwt.Subject) {
+	vr := jwt.CreateValidationResults()
+	subject.Validate(vr)
+	v.results(path, subject, vr)
+}
+
+// permission checks the syntax of the subjects of a permission.
+func (v *validation) permission(path *field.Path, p natsv1alpha1.Permission, permitQueue bool) {
+	for i, subj := range p.Allow {
+		vr := jwt.CreateValidationResults()
+		(&jwt.Permission{Allow: jwt.StringList{subj}}).Validate(vr, permitQueue)
+		v.results(path.Child("allow").Index(i), subj, vr)
+	}
+
+	for i, subj := range p.Deny {
+		vr := jwt.CreateValidationResults()
+		(&jwt.Permission{Deny: jwt.StringList{subj}}).Validate(vr, permitQueue)
+		v.results(path.Child("deny").Index(i), subj, vr)
+	}
+}
+
+// publish checks the name and the keys of the published Secret or ConfigMap.
+func (v *validation) publish(path *field.Path, spec *natsv1alpha1.PublishSpec) {
+	if spec == nil {
+		return
+	}
+
+	if spec.Name != "" {
+		for _, msg := range utilvalidation.IsDNS1123Subdomain(spec.Name) {
+			v.errs = append(v.errs, field.Invalid(path.Child("name"), spec.Name, msg))
+		}
+	}
+
+	v.dataKey(path.Child("jwtKey"), spec.JWTKey)
+	v.dataKey(path.Child("publicKeyKey"), spec.PublicKeyKey)
+
+	if spec.JWTKey != "" && spec.JWTKey == spec.PublicKeyKey {
+		v.errs = append(v.errs, field.Duplicate(path.Child("publicKeyKey"), spec.PublicKeyKey))
+	}
+}
+
+// dataKey checks that an optional key is a valid key of a Secret or ConfigMap.
+func (v *validation) dataKey(path *field.Path, key string) {
+	if key == "" {
+		return
+	}
+
+	for _, msg := range utilvalidation.IsConfigMapKey(key) {
+		v.errs = append(v.errs, field.Invalid(path, key, msg))
+	}
+}
+
+// results adds the issues of the validation results of a field.
+func (v *validation) results(path *field.Path, value any, vr *jwt.ValidationResults) {
+	for _, err := range vr.Errors() {
+		v.errs = append(v.errs, field.Invalid(path, value, err.Error()))
+	}
+
+	for _, w := range vr.Warnings() {
+		v.warnings = append(v.warnings, fmt.Sprintf("%s: %s", path, w))
+	}
+}
+
+// result returns the warnings and an invalid error for the object of the kind if there are any errors.
+func (v *validation) result(kind string, obj client.Object) (admission.Warnings, error) {
+	if len(v.errs) == 0 {
+		return v.warnings, nil
+	}
+
+	return v.warnings, errors.NewInvalid(natsv1alpha1.SchemeGroupVersion.WithKind(kind).GroupKind(), obj.GetName(), v.errs)
+}
+
+// isDeleting returns true if the object is being deleted.
+// Updates of deleting objects are not validated to not block the removal of finalizers.
+func isDeleting(obj client.Object) bool {
+	return !obj.GetDeletionTimestamp().IsZero()
+}
@@ -0,0 +1,257 @@
+package webhooks
+
+import (
+	"context"
+	"testing"
+
+	natsv1alpha1 "github.com/katallaxie/natz-operator/api/v1alpha1"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newFakeReader(t *testing.T, objs ...client.Object) client.Reader {
+	t.Helper()
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	require.NoError(t, natsv1alpha1.AddToScheme(scheme))
+
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+}
+
+func newKey(name string, keyType natsv1alpha1.KeyType) *natsv1alpha1.NatsKey {
+	return &natsv1alpha1.NatsKey{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+		Spec:       natsv1alpha1.NatsKeySpec{Type: keyType},
+	}
+}
+
+func TestValidationSubject(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		subject jwt.Subject
+		valid   bool
+	}{
+		{name: "literal", subject: "foo.bar", valid: true},
+		{name: "wildcard", subject: "foo.*", valid: true},
+		{name: "full wildcard", subject: "foo.>", valid: true},
+		{name: "empty", subject: "", valid: false},
+		{name: "whitespace", subject: "foo bar", valid: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			val := newValidation(nil)
+			val.subject(field.NewPath("spec", "subject"), tc.subject)
+			require.Equal(t, tc.valid, len(val.errs) == 0, val.errs)
+		})
+	}
+}
+
+func TestValidationPermission(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		permission  natsv1alpha1.Permission
+		permitQueue bool
+		errs        int
+	}{
+		{name: "empty", permission: natsv1alpha1.Permission{}, errs: 0},
+		{name: "allow and deny", permission: natsv1alpha1.Permission{Allow: []string{"foo.>"}, Deny: []string{"foo.bar"}}, errs: 0},
+		{name: "queue", permission: natsv1alpha1.Permission{Allow: []string{"foo.> workers"}}, permitQueue: true, errs: 0},
+		{name: "queue not permitted", permission: natsv1alpha1.Permission{Allow: []string{"foo.> workers"}}, errs: 1},
+		{name: "invalid allow and deny", permission: natsv1alpha1.Permission{Allow: []string{"foo. bar baz"}, Deny: []string{"foo bar baz"}}, permitQueue: true, errs: 2},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			val := newValidation(nil)
+			val.permission(field.NewPath("spec", "permissions", "pub"), tc.permission, tc.permitQueue)
+			require.Len(t, val.errs, tc.errs, val.errs)
+		})
+	}
+}
+
+func TestValidationKeyRef(t *testing.T) {
+	t.Parallel()
+
+	reader := newFakeReader(t, newKey("account-key", natsv1alpha1.KeyTypeAccount))
+
+	tests := []struct {
+		name     string
+		key      string
+		types    []natsv1alpha1.KeyType
+		errs     int
+		warnings int
+	}{
+		{name: "type", key: "account-key", types: []natsv1alpha1.KeyType{natsv1alpha1.KeyTypeAccount}},
+		{name: "one of the types", key: "account-key", types: []natsv1alpha1.KeyType{natsv1alpha1.KeyTypeOperator, natsv1alpha1.KeyTypeAccount}},
+		{name: "other type", key: "account-key", types: []natsv1alpha1.KeyType{natsv1alpha1.KeyTypeUser}, errs: 1},
+		{name: "missing name", key: "", types: []natsv1alpha1.KeyType{natsv1alpha1.KeyTypeAccount}, errs: 1},
+		{name: "not found", key: "other-key", types: []natsv1alpha1.KeyType{natsv1alpha1.KeyTypeAccount}, warnings: 1},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			val := newValidation(reader)
+			err := val.keyRef(context.Background(), field.NewPath("spec", "signerKeyRef"), client.ObjectKey{Namespace: "default", Name: tc.key}, tc.types...)
+			require.NoError(t, err)
+			require.Len(t, val.errs, tc.errs, val.errs)
+			require.Len(t, val.warnings, tc.warnings, val.warnings)
+		})
+	}
+}
+
+func TestAccountKeys(t *testing.T) {
+	t.Parallel()
+
+	account := &natsv1alpha1.NatsAccount{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "account"},
+		Spec: natsv1alpha1.NatsAccountSpec{
+			PrivateKey:        natsv1alpha1.NatsKeyReference{Name: "private-key"},
+			SigningKeys:       []natsv1alpha1.NatsKeyReference{{Name: "signing-key"}},
+			ScopedSigningKeys: []natsv1alpha1.ScopedSigningKey{{NatsKeyReference: natsv1alpha1.NatsKeyReference{Name: "scoped-key"}, Role: "team"}},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		namespace string
+		expected  []client.ObjectKey
+	}{
+		{
+			name:      "same namespace",
+			namespace: "default",
+			expected: []client.ObjectKey{
+				{Namespace: "default", Name: "private-key"},
+				{Namespace: "default", Name: "signing-key"},
+				{Namespace: "default", Name: "scoped-key"},
+			},
+		},
+		{
+			name:      "other namespace",
+			namespace: "team",
+			expected: []client.ObjectKey{
+				{Namespace: "default", Name: "scoped-key"},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tc.expected, accountKeys(account, tc.namespace))
+		})
+	}
+}
+
+func TestNatsKeyValidatorTypeImmutable(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		old   natsv1alpha1.KeyType
+		new   natsv1alpha1.KeyType
+		valid bool
+	}{
+		{name: "unchanged", old: natsv1alpha1.KeyTypeAccount, new: natsv1alpha1.KeyTypeAccount, valid: true},
+		{name: "changed", old: natsv1alpha1.KeyTypeAccount, new: natsv1alpha1.KeyTypeUser, valid: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			v := &NatsKeyValidator{Reader: newFakeReader(t)}
+
+			_, err := v.ValidateUpdate(context.Background(), newKey("key", tc.old), newKey("key", tc.new))
+			if tc.valid {
+				require.NoError(t, err)
+				return
+			}
+
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "spec.type")
+		})
+	}
+}
+
+func TestNatsKeyValidatorValidateSeed(t *testing.T) {
+	t.Parallel()
+
+	kp, err := nkeys.CreateAccount()
+	require.NoError(t, err)
+
+	seed, err := kp.Seed()
+	require.NoError(t, err)
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "seed"},
+		Data:       map[string][]byte{"seed.nk": seed},
+	}
+
+	reader := newFakeReader(t, secret)
+
+	tests := []struct {
+		name     string
+		keyType  natsv1alpha1.KeyType
+		secret   string
+		key      string
+		rotation bool
+		errs     int
+		warnings int
+	}{
+		{name: "seed of the type", keyType: natsv1alpha1.KeyTypeAccount, secret: "seed", key: "seed.nk"},
+		{name: "seed of another type", keyType: natsv1alpha1.KeyTypeUser, secret: "seed", key: "seed.nk", errs: 1},
+		{name: "missing key", keyType: natsv1alpha1.KeyTypeAccount, secret: "seed", key: "", errs: 1},
+		{name: "key not in secret", keyType: natsv1alpha1.KeyTypeAccount, secret: "seed", key: "other.nk", errs: 1},
+		{name: "secret not found", keyType: natsv1alpha1.KeyTypeAccount, secret: "other", key: "seed.nk", warnings: 1},
+		{name: "rotation", keyType: natsv1alpha1.KeyTypeAccount, secret: "seed", key: "seed.nk", rotation: true, errs: 1},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			obj := newKey("key", tc.keyType)
+			obj.Spec.Seed.SecretKeyRef = &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: tc.secret},
+				Key:                  tc.key,
+			}
+
+			if tc.rotation {
+				obj.Spec.Rotation = &natsv1alpha1.KeyRotation{}
+			}
+
+			v := &NatsKeyValidator{Reader: reader}
+			val := newValidation(reader)
+
+			err := v.validateSeed(context.Background(), val, field.NewPath("spec", "seed"), obj)
+			require.NoError(t, err)
+			require.Len(t, val.errs, tc.errs, val.errs)
+			require.Len(t, val.warnings, tc.warnings, val.warnings)
+
+			for _, e := range val.errs {
+				require.NotContains(t, e.Error(), string(seed))
+			}
+		})
+	}
+}